@@ -0,0 +1,310 @@
+package uphold
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// currencyPrecisions are the number of decimal places in which uphold expresses amounts of the given currencies
+var currencyPrecisions = map[string]int32{
+	"AED": 2, "ARS": 2, "AUD": 2, "BAT": 8, "BCH": 8, "BRL": 2, "BTC": 8, "BTG": 8, "CAD": 2, "CHF": 2,
+	"CNY": 2, "DASH": 8, "DKK": 2, "ETH": 8, "EUR": 2, "GBP": 2, "HKD": 2, "ILS": 2, "INR": 2, "JPY": 0,
+	"KES": 2, "LTC": 8, "MXN": 2, "NOK": 2, "NZD": 2, "PHP": 2, "PLN": 2, "SEK": 2, "SGD": 2, "USD": 2,
+	"XAG": 2, "XAU": 2, "XPD": 2, "XPT": 2, "XRP": 6, "XLM": 7,
+}
+
+// maxAmountScale bounds the exponent and the number of digits after the decimal point accepted by
+// ParseAmount, so that malformed API responses cannot force arbitrarily large allocations
+const maxAmountScale = 300
+
+// Amount is an exact decimal amount; uphold expresses amounts as JSON strings, which are preserved
+// without loss of precision, including trailing zeros. The zero value is 0. Amount values are immutable.
+type Amount struct {
+	unscaled *big.Int // the amount multiplied by 10^scale
+	scale    int32    // the number of digits after the decimal point
+}
+
+// NewAmount returns the Amount unscaled * 10^-scale, e.g., NewAmount(12345, 2) is 123.45
+func NewAmount(unscaled int64, scale int32) Amount {
+	return newAmount(big.NewInt(unscaled), scale)
+}
+
+// NewAmountFromFloat returns the Amount with the shortest decimal representation of the given float64
+func NewAmountFromFloat(f float64) Amount {
+	amount, err := ParseAmount(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Amount{}
+	}
+	return amount
+}
+
+// ParseAmount parses a decimal amount, e.g., 0.00001234, -5 or 1.5e-8
+func ParseAmount(str string) (Amount, error) {
+	s := strings.TrimSpace(str)
+	if s == "" {
+		return Amount{}, fmt.Errorf("invalid amount: %q", str)
+	}
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Amount{}, fmt.Errorf("invalid amount: %q", str)
+		}
+		if exp > maxAmountScale || exp < -maxAmountScale {
+			return Amount{}, fmt.Errorf("invalid amount: %q; exponent out of range", str)
+		}
+		s = s[:i]
+	}
+
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Amount{}, fmt.Errorf("invalid amount: %q", str)
+	}
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return Amount{}, fmt.Errorf("invalid amount: %q", str)
+		}
+	}
+
+	unscaled, ok := new(big.Int).SetString(sign+intPart+fracPart, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid amount: %q", str)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale > maxAmountScale {
+		return Amount{}, fmt.Errorf("invalid amount: %q; too many decimal places", str)
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+
+	return newAmount(unscaled, int32(scale)), nil
+}
+
+// MustParseAmount parses a decimal amount, panicking if it is malformed
+func MustParseAmount(str string) Amount {
+	amount, err := ParseAmount(str)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+func newAmount(unscaled *big.Int, scale int32) Amount {
+	return Amount{unscaled: unscaled, scale: scale}
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// int returns the unscaled value, which is never nil
+func (a Amount) int() *big.Int {
+	if a.unscaled == nil {
+		return new(big.Int)
+	}
+	return a.unscaled
+}
+
+// rescale returns the unscaled value of the amount expressed with the given, larger scale
+func (a Amount) rescale(scale int32) *big.Int {
+	if scale <= a.scale {
+		return new(big.Int).Set(a.int())
+	}
+	return new(big.Int).Mul(a.int(), pow10(int64(scale-a.scale)))
+}
+
+// Scale returns the number of digits after the decimal point
+func (a Amount) Scale() int32 {
+	return a.scale
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	scale := max(a.scale, b.scale)
+	return newAmount(new(big.Int).Add(a.rescale(scale), b.rescale(scale)), scale)
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	scale := max(a.scale, b.scale)
+	return newAmount(new(big.Int).Sub(a.rescale(scale), b.rescale(scale)), scale)
+}
+
+// Mul returns a * b
+func (a Amount) Mul(b Amount) Amount {
+	return newAmount(new(big.Int).Mul(a.int(), b.int()), a.scale+b.scale)
+}
+
+// Quo returns a / b rounded half away from zero to the given number of decimal places, which may be negative
+// to round to tens, hundreds and so on; it panics if b is zero
+func (a Amount) Quo(b Amount, places int32) Amount {
+	if b.Sign() == 0 {
+		panic("uphold: division of amount by zero")
+	}
+
+	// a / b = (ua / ub) * 10^(sb-sa); compute with one extra digit for rounding, scaling whichever
+	// operand keeps the exponent non-negative so that negative places are supported
+	num := new(big.Int).Set(a.int())
+	den := new(big.Int).Set(b.int())
+	if exp := int64(places) + 1 + int64(b.scale) - int64(a.scale); exp >= 0 {
+		num.Mul(num, pow10(exp))
+	} else {
+		den.Mul(den, pow10(-exp))
+	}
+	quo := new(big.Int).Quo(num, den)
+	return newAmount(quo, places+1).Round(places)
+}
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	return newAmount(new(big.Int).Neg(a.int()), a.scale)
+}
+
+// Abs returns |a|
+func (a Amount) Abs() Amount {
+	return newAmount(new(big.Int).Abs(a.int()), a.scale)
+}
+
+// Sign returns -1, 0 or 1 when the amount is negative, zero or positive
+func (a Amount) Sign() int {
+	return a.int().Sign()
+}
+
+// IsZero returns true if the amount is zero
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Cmp returns -1, 0 or 1 when a is less than, equal to or greater than b
+func (a Amount) Cmp(b Amount) int {
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale).Cmp(b.rescale(scale))
+}
+
+// Equal returns true if a and b are numerically equal, regardless of scale
+func (a Amount) Equal(b Amount) bool {
+	return a.Cmp(b) == 0
+}
+
+// Round returns the amount rounded half away from zero to the given number of decimal places
+func (a Amount) Round(places int32) Amount {
+	if places >= a.scale {
+		return newAmount(a.rescale(places), places)
+	}
+
+	divisor := pow10(int64(a.scale - places))
+	quo, rem := new(big.Int).QuoRem(a.int(), divisor, new(big.Int))
+	rem.Abs(rem).Mul(rem, big.NewInt(2))
+	if rem.Cmp(divisor) >= 0 {
+		if a.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return newAmount(quo, places)
+}
+
+// Truncate returns the amount truncated toward zero to the given number of decimal places
+func (a Amount) Truncate(places int32) Amount {
+	if places >= a.scale {
+		return newAmount(a.rescale(places), places)
+	}
+	return newAmount(new(big.Int).Quo(a.int(), pow10(int64(a.scale-places))), places)
+}
+
+// RoundCurrency returns the amount rounded to the precision in which uphold expresses amounts of the
+// given currency, e.g., 8 decimal places for BTC; the amount is returned unchanged for unknown currencies
+func (a Amount) RoundCurrency(currency string) Amount {
+	if places, placesOk := currencyPrecisions[strings.ToUpper(currency)]; placesOk {
+		return a.Round(places)
+	}
+	return a
+}
+
+// Float64 returns the nearest float64 to the amount; precision may be lost
+func (a Amount) Float64() float64 {
+	f, _ := strconv.ParseFloat(a.String(), 64)
+	return f
+}
+
+// String returns the amount in plain decimal notation, preserving its scale, e.g., 146.380
+func (a Amount) String() string {
+	digits := new(big.Int).Abs(a.int()).String()
+	sign := ""
+	if a.Sign() < 0 {
+		sign = "-"
+	}
+
+	if a.scale <= 0 {
+		if a.Sign() != 0 && a.scale < 0 {
+			digits += strings.Repeat("0", int(-a.scale))
+		}
+		return sign + digits
+	}
+
+	if len(digits) <= int(a.scale) {
+		digits = strings.Repeat("0", int(a.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(a.scale)
+	return fmt.Sprintf("%s%s.%s", sign, digits[:point], digits[point:])
+}
+
+// MarshalJSON encodes the amount as a JSON string, as uphold does
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON decodes the amount from either a JSON string or number; null leaves the amount unchanged
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	str := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+	}
+
+	amount, err := ParseAmount(str)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalText encodes the amount in plain decimal notation
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an amount in decimal notation
+func (a *Amount) UnmarshalText(text []byte) error {
+	amount, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
@@ -0,0 +1,190 @@
+package uphold
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string // empty if parsing must fail
+	}{
+		{"0.00001234", "0.00001234"},
+		{"146.380", "146.380"},
+		{"-5", "-5"},
+		{"+2.0", "2.0"},
+		{".5", "0.5"},
+		{"-0.50", "-0.50"},
+		{"1.5e-8", "0.000000015"},
+		{"1.5E2", "150"},
+		{"1e3", "1000"},
+		{" 7.10 ", "7.10"},
+		{"1e300", "1" + strings.Repeat("0", 300)},
+		{"1e-300", "0." + strings.Repeat("0", 299) + "1"},
+		{"1.5e-299", "0." + strings.Repeat("0", 298) + "15"},
+		{"0." + strings.Repeat("1", 300), "0." + strings.Repeat("1", 300)},
+		{"", ""},
+		{"abc", ""},
+		{"1.2.3", ""},
+		{"--1", ""},
+		{"1e", ""},
+		{".", ""},
+		{"1 000", ""},
+		{"1e301", ""},
+		{"1e-301", ""},
+		{"1.55e-299", ""},
+		{"0." + strings.Repeat("1", 301), ""},
+		{"1e-2147483648", ""},
+		{"1e2000000000", ""},
+		{"1e99999999999", ""},
+	}
+
+	for _, test := range tests {
+		amount, err := ParseAmount(test.in)
+		if test.out == "" {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) = %s; want error", test.in, amount)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error: %s", test.in, err)
+			continue
+		}
+		if amount.String() != test.out {
+			t.Errorf("ParseAmount(%q) = %s; want %s", test.in, amount, test.out)
+		}
+	}
+}
+
+func TestAmountRound(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		round  string
+		trunc  string
+	}{
+		{"2.345", 2, "2.35", "2.34"},
+		{"-2.345", 2, "-2.35", "-2.34"},
+		{"2.344", 2, "2.34", "2.34"},
+		{"2.349", 2, "2.35", "2.34"},
+		{"-2.349", 2, "-2.35", "-2.34"},
+		{"0.5", 0, "1", "0"},
+		{"-0.5", 0, "-1", "0"},
+		{"1.2", 3, "1.200", "1.200"},
+		{"1550", -2, "1600", "1500"},
+		{"-1550", -2, "-1600", "-1500"},
+		{"1549.99", -2, "1500", "1500"},
+	}
+
+	for _, test := range tests {
+		amount := MustParseAmount(test.in)
+		if round := amount.Round(test.places).String(); round != test.round {
+			t.Errorf("%s.Round(%d) = %s; want %s", test.in, test.places, round, test.round)
+		}
+		if trunc := amount.Truncate(test.places).String(); trunc != test.trunc {
+			t.Errorf("%s.Truncate(%d) = %s; want %s", test.in, test.places, trunc, test.trunc)
+		}
+	}
+}
+
+func TestAmountQuo(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		places int32
+		quo    string
+	}{
+		{"1", "3", 4, "0.3333"},
+		{"2", "3", 4, "0.6667"},
+		{"-2", "3", 4, "-0.6667"},
+		{"2", "-3", 4, "-0.6667"},
+		{"1", "8", 2, "0.13"},
+		{"-1", "8", 2, "-0.13"},
+		{"10.00", "4", 1, "2.5"},
+		{"0.001", "0.0001", 0, "10"},
+		{"1500", "1", -2, "1500"},
+		{"1550", "1", -2, "1600"},
+		{"1500", "0.01", -3, "150000"},
+		{"12345", "10", -1, "1230"},
+	}
+
+	for _, test := range tests {
+		quo := MustParseAmount(test.a).Quo(MustParseAmount(test.b), test.places)
+		if quo.String() != test.quo {
+			t.Errorf("%s.Quo(%s, %d) = %s; want %s", test.a, test.b, test.places, quo, test.quo)
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := MustParseAmount("1.50")
+	b := MustParseAmount("-0.255")
+
+	if sum := a.Add(b).String(); sum != "1.245" {
+		t.Errorf("Add = %s; want 1.245", sum)
+	}
+	if diff := a.Sub(b).String(); diff != "1.755" {
+		t.Errorf("Sub = %s; want 1.755", diff)
+	}
+	if prod := a.Mul(b).String(); prod != "-0.38250" {
+		t.Errorf("Mul = %s; want -0.38250", prod)
+	}
+	if !MustParseAmount("1.50").Equal(MustParseAmount("1.5")) || a.Cmp(b) != 1 || b.Sign() != -1 {
+		t.Errorf("unexpected comparison results")
+	}
+	if rounded := MustParseAmount("0.123456789").RoundCurrency("btc").String(); rounded != "0.12345679" {
+		t.Errorf("RoundCurrency(btc) = %s; want 0.12345679", rounded)
+	}
+	if zero := (Amount{}).String(); zero != "0" {
+		t.Errorf("zero value = %s; want 0", zero)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string // empty if decoding must fail
+	}{
+		{`"146.380"`, `"146.380"`},
+		{`"-0.00"`, `"0.00"`},
+		{`1.50`, `"1.50"`},
+		{`-5`, `"-5"`},
+		{`1e-8`, `"0.00000001"`},
+		{`"abc"`, ""},
+		{`"1e-2147483648"`, ""},
+		{`true`, ""},
+	}
+
+	for _, test := range tests {
+		var amount Amount
+		err := json.Unmarshal([]byte(test.in), &amount)
+		if test.out == "" {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %s; want error", test.in, amount)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s) returned error: %s", test.in, err)
+			continue
+		}
+
+		out, err := json.Marshal(amount)
+		if err != nil {
+			t.Errorf("Marshal(%s) returned error: %s", test.in, err)
+			continue
+		}
+		if string(out) != test.out {
+			t.Errorf("round trip of %s = %s; want %s", test.in, out, test.out)
+		}
+	}
+
+	var card struct {
+		Balance *Amount `json:"balance"`
+	}
+	if err := json.Unmarshal([]byte(`{"balance":null}`), &card); err != nil || card.Balance != nil {
+		t.Errorf("expected null amount to decode as nil; got %v, %v", card.Balance, err)
+	}
+}
@@ -3,10 +3,13 @@ package uphold
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,6 +17,26 @@ import (
 )
 
 const defaultContentType = "application/json"
+const defaultRequestTimeout = time.Second * 30
+
+// defaultHTTPClient is shared by all APIClient instances which have not been configured with their own
+// *http.Client; its transport pools keep-alive connections and honors the environment-configured proxy
+var defaultHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+	Timeout: defaultRequestTimeout,
+}
 
 // APIClient is a generic base class for calling the uphold API
 type APIClient struct {
@@ -23,78 +46,105 @@ type APIClient struct {
 	Token    *string
 	Username *string
 	Password *string
+
+	// HTTPClient is used to send requests; when nil, a package-wide client with a pooled transport is used
+	HTTPClient *http.Client
+
+	// RetryPolicy determines how failed requests are retried; when nil, DefaultRetryPolicy is used
+	RetryPolicy *RetryPolicy
+
+	// RateLimiter tracks the uphold request quota and optionally throttles requests; may be nil
+	RateLimiter *RateLimiter
+
+	// OTPProvider answers one-time password challenges, after which the request is retried once; may be nil
+	OTPProvider OTPProvider
+}
+
+// APIClientOption configures optional behavior of an APIClient
+type APIClientOption func(*APIClient)
+
+// WithHTTPClient configures the APIClient to send requests using the given *http.Client, which may
+// be shared across many APIClient instances in order to reuse connections
+func WithHTTPClient(client *http.Client) APIClientOption {
+	return func(c *APIClient) {
+		c.HTTPClient = client
+	}
+}
+
+// WithTransport configures the APIClient to send requests using the given http.RoundTripper
+func WithTransport(transport http.RoundTripper) APIClientOption {
+	return func(c *APIClient) {
+		c.HTTPClient = &http.Client{
+			Transport: transport,
+			Timeout:   defaultRequestTimeout,
+		}
+	}
 }
 
 // NewUpholdAPIClient initializes an APIClient using the environment-configured client id and secret
 // to construct an HTTP basic authorization header, unless a non-nil bearer access token is provided.
-func NewUpholdAPIClient(token, baseURI *string) (*APIClient, error) {
-	apiURL, err := url.Parse(upholdAPIBaseURL)
+func NewUpholdAPIClient(token, baseURI *string, opts ...APIClientOption) (*APIClient, error) {
+	client, err := DefaultClient()
 	if err != nil {
-		log.Warningf("Failed to parse uphold API base url; %s", err.Error())
 		return nil, err
 	}
+	return client.NewAPIClient(token, baseURI, opts...)
+}
 
-	path := ""
-	if baseURI != nil {
-		path = *baseURI
+// NewUnauthorizedAPIClient initializes an APIClient without API credentials
+func NewUnauthorizedAPIClient(baseURI *string, opts ...APIClientOption) (*APIClient, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
 	}
+	return client.NewUnauthorizedAPIClient(baseURI, opts...)
+}
 
-	var client *APIClient
+func (c *APIClient) sendRequest(ctx context.Context, method, urlString, contentType string, params map[string]interface{}, response interface{}) (status int, err error) {
+	status, _, err = c.sendRequestWithHeaders(ctx, method, urlString, contentType, params, nil, response)
+	return status, err
+}
 
-	if token != nil {
-		client = &APIClient{
-			Host:   apiURL.Host,
-			Scheme: apiURL.Scheme,
-			Path:   path,
-			Token:  token,
-		}
-	} else {
-		client = &APIClient{
-			Host:     apiURL.Host,
-			Scheme:   apiURL.Scheme,
-			Path:     path,
-			Username: stringOrNil(upholdClientID),
-			Password: stringOrNil(upholdClientSecret),
-		}
+// sendRequestWithHeaders sends the request with the given additional request headers, returning the response headers alongside the status;
+// a one-time password challenge is answered using the OTPProvider, if any, unless the context already provides a password
+func (c *APIClient) sendRequestWithHeaders(ctx context.Context, method, urlString, contentType string, params map[string]interface{}, reqHeader http.Header, response interface{}) (status int, respHeader http.Header, err error) {
+	otp := otpFromContext(ctx)
+	if otp != nil {
+		reqHeader = otp.header(reqHeader)
 	}
 
-	return client, nil
-}
+	status, respHeader, err = c.doRequest(ctx, method, urlString, contentType, params, reqHeader, response)
 
-// NewUnauthorizedAPIClient initializes an APIClient without API credentials
-func NewUnauthorizedAPIClient(baseURI *string) (*APIClient, error) {
-	apiURL, err := url.Parse(upholdAPIBaseURL)
-	if err != nil {
-		log.Warningf("Failed to parse uphold API base url; %s", err.Error())
-		return nil, err
+	var otpErr *OTPRequiredError
+	if c.OTPProvider == nil || otp != nil || !errors.As(err, &otpErr) {
+		return status, respHeader, err
 	}
 
-	path := ""
-	if baseURI != nil {
-		path = *baseURI
+	otp, err = c.OTPProvider(ctx, otpErr)
+	if err != nil {
+		log.Warningf("Failed to obtain one-time password for uphold API (%s %s) invocation; %s", method, urlString, err.Error())
+		return status, respHeader, err
+	}
+	if otp == nil {
+		return status, respHeader, otpErr
+	}
+	if otp.MethodID == "" {
+		otp.MethodID = otpErr.MethodID
 	}
 
-	return &APIClient{
-		Host:   apiURL.Host,
-		Scheme: apiURL.Scheme,
-		Path:   path,
-		Token:  nil,
-	}, nil
+	log.Debugf("Retrying uphold API (%s %s) invocation with one-time password", method, urlString)
+	return c.doRequest(ctx, method, urlString, contentType, params, otp.header(reqHeader), response)
 }
 
-func (c *APIClient) sendRequest(method, urlString, contentType string, params map[string]interface{}, response interface{}) (status int, err error) {
-	client := &http.Client{
-		Transport: &http.Transport{
-			DisableKeepAlives: true,
-		},
-		Timeout: time.Second * 30,
-	}
+// doRequest sends the request with the given additional request headers, retrying transient failures per the retry policy
+func (c *APIClient) doRequest(ctx context.Context, method, urlString, contentType string, params map[string]interface{}, reqHeader http.Header, response interface{}) (status int, respHeader http.Header, err error) {
+	client := c.httpClient()
 
 	mthd := strings.ToUpper(method)
 	reqURL, err := url.Parse(urlString)
 	if err != nil {
 		log.Warningf("Failed to parse URL for uphold API (%s %s) invocation; %s", method, urlString, err.Error())
-		return -1, err
+		return -1, nil, err
 	}
 
 	if mthd == "GET" && params != nil {
@@ -117,16 +167,18 @@ func (c *APIClient) sendRequest(method, urlString, contentType string, params ma
 	} else if c.Token != nil {
 		headers["Authorization"] = []string{fmt.Sprintf("Bearer %s", *c.Token)}
 	}
+	for name, vals := range reqHeader {
+		headers[name] = vals
+	}
 
-	var req *http.Request
+	var payload []byte
 
-	if mthd == "POST" || mthd == "PUT" {
-		var payload []byte
+	if mthd == "POST" || mthd == "PUT" || mthd == "PATCH" {
 		if contentType == "application/json" {
 			payload, err = json.Marshal(params)
 			if err != nil {
 				log.Warningf("Failed to marshal JSON payload for uphold API (%s %s) invocation; %s", method, urlString, err.Error())
-				return -1, err
+				return -1, nil, err
 			}
 		} else if contentType == "application/x-www-form-urlencoded" {
 			urlEncodedForm := url.Values{}
@@ -140,24 +192,75 @@ func (c *APIClient) sendRequest(method, urlString, contentType string, params ma
 			payload = []byte(urlEncodedForm.Encode())
 		}
 
-		req, _ = http.NewRequest(method, urlString, bytes.NewReader(payload))
 		headers["Content-Type"] = []string{contentType}
-	} else {
-		req = &http.Request{
-			URL:    reqURL,
-			Method: mthd,
-		}
 	}
 
-	req.Header = headers
+	policy := c.retryPolicy()
+	retryable := policy.allows(ctx, mthd)
 
-	resp, err := client.Do(req)
-	if resp != nil && resp.Body != nil {
-		defer resp.Body.Close()
-	}
-	if err != nil {
-		log.Warningf("Failed to invoke uphold API (%s %s) method: %s; %s", method, urlString, err.Error())
-		return 0, err
+	var resp *http.Response
+
+	for attempt := 1; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, mthd, reqURL.String(), body)
+		if err != nil {
+			log.Warningf("Failed to build uphold API (%s %s) request; %s", method, urlString, err.Error())
+			return -1, nil, err
+		}
+		req.Header = http.Header(headers).Clone()
+
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx); err != nil {
+				log.Warningf("Failed to invoke uphold API (%s %s) method; %s", method, urlString, err.Error())
+				return 0, nil, err
+			}
+		}
+
+		resp, err = client.Do(req)
+		if resp != nil && c.RateLimiter != nil {
+			c.RateLimiter.update(resp.Header)
+		}
+		if !retryable || attempt >= policy.MaxAttempts || !shouldRetry(ctx, resp, err) {
+			if resp != nil && resp.Body != nil {
+				defer resp.Body.Close()
+			}
+			if err != nil {
+				log.Warningf("Failed to invoke uphold API (%s %s) method: %s; %s", method, urlString, err.Error())
+				return 0, nil, err
+			}
+			break
+		}
+
+		retry := &RetryAttempt{
+			Method:  mthd,
+			URL:     urlString,
+			Attempt: attempt,
+			Err:     err,
+			Backoff: policy.backoff(attempt, resp),
+		}
+		if resp != nil {
+			retry.StatusCode = resp.StatusCode
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		log.Debugf("Retrying uphold API (%s %s) invocation in %v after attempt %d failed; status code: %d", method, urlString, retry.Backoff, attempt, retry.StatusCode)
+		if policy.OnRetry != nil {
+			policy.OnRetry(retry)
+		}
+
+		timer := time.NewTimer(retry.Backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Warningf("Failed to invoke uphold API (%s %s) method; %s", method, urlString, ctx.Err().Error())
+			return 0, nil, ctx.Err()
+		case <-timer.C:
+		}
 	}
 
 	log.Debugf("Received %v response for uphold API (%s %s) invocation", resp.StatusCode, method, urlString)
@@ -166,6 +269,10 @@ func (c *APIClient) sendRequest(method, urlString, contentType string, params ma
 	switch resp.Header.Get("Content-Encoding") {
 	case "gzip":
 		reader, err = gzip.NewReader(resp.Body)
+		if err != nil {
+			log.Warningf("Failed to decompress uphold API (%s %s) response; %s", method, urlString, err.Error())
+			return resp.StatusCode, resp.Header, err
+		}
 		defer reader.Close()
 	default:
 		reader = resp.Body
@@ -173,43 +280,121 @@ func (c *APIClient) sendRequest(method, urlString, contentType string, params ma
 
 	buf := new(bytes.Buffer)
 	buf.ReadFrom(reader)
+
+	if resp.StatusCode >= 400 {
+		apiErr := newError(mthd, urlString, resp, buf.Bytes())
+		log.Warningf("Invocation of uphold API (%s %s) failed; %s", method, urlString, apiErr.Error())
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return resp.StatusCode, resp.Header, newRateLimitError(apiErr)
+		}
+		if isOTPChallenge(apiErr) {
+			return resp.StatusCode, resp.Header, newOTPRequiredError(apiErr)
+		}
+		return resp.StatusCode, resp.Header, apiErr
+	}
+
+	if buf.Len() == 0 || response == nil {
+		log.Debugf("Invocation of uphold API (%s %s) succeeded (%v-byte response)", method, urlString, buf.Len())
+		return resp.StatusCode, resp.Header, nil
+	}
+
 	err = json.Unmarshal(buf.Bytes(), &response)
 	if err != nil {
-		return resp.StatusCode, fmt.Errorf("Failed to unmarshal uphold API (%s %s) response: %s; %s", method, urlString, buf.Bytes(), err.Error())
+		return resp.StatusCode, resp.Header, fmt.Errorf("Failed to unmarshal uphold API (%s %s) response: %s; %s", method, urlString, buf.Bytes(), err.Error())
 	}
 
 	log.Debugf("Invocation of uphold API (%s %s) succeeded (%v-byte response)", method, urlString, buf.Len())
-	return resp.StatusCode, nil
+	return resp.StatusCode, resp.Header, nil
 }
 
 // Get constructs and synchronously sends an API GET request
 func (c *APIClient) Get(uri string, params map[string]interface{}, response interface{}) (status int, err error) {
+	return c.GetContext(context.Background(), uri, params, response)
+}
+
+// GetContext constructs and synchronously sends an API GET request bound to the given context
+func (c *APIClient) GetContext(ctx context.Context, uri string, params map[string]interface{}, response interface{}) (status int, err error) {
 	url := c.buildURL(uri)
-	return c.sendRequest("GET", url, defaultContentType, params, response)
+	return c.sendRequest(ctx, "GET", url, defaultContentType, params, response)
+}
+
+// GetRangeContext constructs and synchronously sends an API GET request for the given inclusive range of items
+// of a list endpoint, bound to the given context; the Content-Range returned by uphold is nil if it was not provided
+func (c *APIClient) GetRangeContext(ctx context.Context, uri string, params map[string]interface{}, start, end int, response interface{}) (status int, contentRange *ContentRange, err error) {
+	url := c.buildURL(uri)
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("items=%d-%d", start, end))
+
+	status, respHeader, err := c.sendRequestWithHeaders(ctx, "GET", url, defaultContentType, params, header, response)
+	if respHeader != nil {
+		contentRange = parseContentRange(respHeader.Get("Content-Range"))
+	}
+	return status, contentRange, err
 }
 
 // Post constructs and synchronously sends an API POST request
 func (c *APIClient) Post(uri string, params map[string]interface{}, response interface{}) (status int, err error) {
+	return c.PostContext(context.Background(), uri, params, response)
+}
+
+// PostContext constructs and synchronously sends an API POST request bound to the given context
+func (c *APIClient) PostContext(ctx context.Context, uri string, params map[string]interface{}, response interface{}) (status int, err error) {
 	url := c.buildURL(uri)
-	return c.sendRequest("POST", url, defaultContentType, params, response)
+	return c.sendRequest(ctx, "POST", url, defaultContentType, params, response)
 }
 
 // PostWWWFormURLEncoded constructs and synchronously sends an API POST request using
 func (c *APIClient) PostWWWFormURLEncoded(uri string, params map[string]interface{}, response interface{}) (status int, err error) {
+	return c.PostWWWFormURLEncodedContext(context.Background(), uri, params, response)
+}
+
+// PostWWWFormURLEncodedContext constructs and synchronously sends an API POST request using
+// application/x-www-form-urlencoded content, bound to the given context
+func (c *APIClient) PostWWWFormURLEncodedContext(ctx context.Context, uri string, params map[string]interface{}, response interface{}) (status int, err error) {
 	url := c.buildURL(uri)
-	return c.sendRequest("POST", url, "application/x-www-form-urlencoded", params, response)
+	return c.sendRequest(ctx, "POST", url, "application/x-www-form-urlencoded", params, response)
 }
 
 // Put constructs and synchronously sends an API PUT request
 func (c *APIClient) Put(uri string, params map[string]interface{}, response interface{}) (status int, err error) {
+	return c.PutContext(context.Background(), uri, params, response)
+}
+
+// PutContext constructs and synchronously sends an API PUT request bound to the given context
+func (c *APIClient) PutContext(ctx context.Context, uri string, params map[string]interface{}, response interface{}) (status int, err error) {
 	url := c.buildURL(uri)
-	return c.sendRequest("PUT", url, defaultContentType, params, response)
+	return c.sendRequest(ctx, "PUT", url, defaultContentType, params, response)
+}
+
+// PatchContext constructs and synchronously sends an API PATCH request bound to the given context
+func (c *APIClient) PatchContext(ctx context.Context, uri string, params map[string]interface{}, response interface{}) (status int, err error) {
+	url := c.buildURL(uri)
+	return c.sendRequest(ctx, "PATCH", url, defaultContentType, params, response)
 }
 
 // Delete constructs and synchronously sends an API DELETE request
 func (c *APIClient) Delete(uri string) (status int, err error) {
+	return c.DeleteContext(context.Background(), uri)
+}
+
+// DeleteContext constructs and synchronously sends an API DELETE request bound to the given context
+func (c *APIClient) DeleteContext(ctx context.Context, uri string) (status int, err error) {
 	url := c.buildURL(uri)
-	return c.sendRequest("DELETE", url, defaultContentType, nil, nil)
+	return c.sendRequest(ctx, "DELETE", url, defaultContentType, nil, nil)
+}
+
+func (c *APIClient) retryPolicy() *RetryPolicy {
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy
+	}
+	return defaultRetryPolicy
+}
+
+func (c *APIClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
 }
 
 func (c *APIClient) buildURL(uri string) string {
@@ -0,0 +1,106 @@
+package uphold
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// applicationTokenTimeout bounds a shared client credentials token request, including retries, so that a hung
+// request cannot block later callers for the same scope indefinitely
+const applicationTokenTimeout = time.Second * 30
+
+// applicationTokenCache holds the client credentials tokens of a Client, keyed by normalized requested scope, and
+// ensures at most one token request per scope is in flight at a time
+type applicationTokenCache struct {
+	mutex    sync.Mutex
+	tokens   map[string]*Token
+	inflight map[string]*applicationTokenCall
+}
+
+// applicationTokenCall is an in-flight client credentials token request, awaited by concurrent callers
+type applicationTokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+func newApplicationTokenCache() *applicationTokenCache {
+	return &applicationTokenCache{
+		tokens:   map[string]*Token{},
+		inflight: map[string]*applicationTokenCall{},
+	}
+}
+
+// ApplicationToken returns a client credentials token for the given space-separated scope, which is cached and reused by
+// all copies of the Client until shortly before it expires; concurrent callers share a single in-flight token request
+func (c *Client) ApplicationToken(ctx context.Context, scope string) (*Token, error) {
+	cache := c.applicationTokens
+	scope = ParseScopes(scope).String()
+
+	cache.mutex.Lock()
+	if token, tokenOk := cache.tokens[scope]; tokenOk && token.Valid() {
+		cache.mutex.Unlock()
+		return token, nil
+	}
+
+	call, inflight := cache.inflight[scope]
+	if !inflight {
+		call = &applicationTokenCall{done: make(chan struct{})}
+		cache.inflight[scope] = call
+		go c.fetchApplicationToken(scope, call)
+	}
+	cache.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetchApplicationToken requests a client credentials token on behalf of all callers awaiting the given call; the
+// request is not bound to any single caller's context, so one caller giving up does not fail the others
+func (c *Client) fetchApplicationToken(scope string, call *applicationTokenCall) {
+	cache := c.applicationTokens
+
+	ctx, cancel := context.WithTimeout(context.Background(), applicationTokenTimeout)
+	defer cancel()
+
+	resp, err := c.AuthorizeClientCredentials(ctx, scope)
+	if err == nil {
+		call.token = resp.Token()
+	}
+	call.err = err
+
+	cache.mutex.Lock()
+	if err == nil {
+		cache.tokens[scope] = call.token
+	}
+	delete(cache.inflight, scope)
+	cache.mutex.Unlock()
+
+	close(call.done)
+}
+
+// evict removes any cached token with the given access token
+func (cache *applicationTokenCache) evict(accessToken string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	for scope, token := range cache.tokens {
+		if token.AccessToken == accessToken {
+			delete(cache.tokens, scope)
+		}
+	}
+}
+
+// ApplicationToken returns the cached client credentials token for the given scope using the environment-configured client
+func ApplicationToken(ctx context.Context, scope string) (*Token, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.ApplicationToken(ctx, scope)
+}
@@ -0,0 +1,244 @@
+package uphold
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// CardUpdate describes the mutable properties of an uphold card; nil properties are left unchanged
+type CardUpdate struct {
+	Label    *string
+	Settings *CardSettings
+}
+
+// Validate returns a *ValidationError if the update does not change any property
+func (u *CardUpdate) Validate() error {
+	if u == nil || (u.Label == nil && u.Settings == nil) {
+		return &ValidationError{Field: "update", Message: "label or settings is required"}
+	}
+	return nil
+}
+
+// CardsPager returns a Pager which walks the cards of the user for the given bearer token
+func (c *Client) CardsPager(token string) (*Pager[*Card], error) {
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPager[*Card](client, "cards", nil, maxPageSize), nil
+}
+
+// ListCards fetches all cards of the user for the given bearer token
+func (c *Client) ListCards(ctx context.Context, token string) ([]*Card, error) {
+	pager, err := c.CardsPager(token)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := pager.All(ctx)
+	if err != nil {
+		log.Warningf("Failed to list uphold cards on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Fetched %d uphold card(s) on behalf of client id: %s", len(cards), c.config.ClientID)
+	return cards, nil
+}
+
+// GetCard fetches the card with the given id for the given bearer token
+func (c *Client) GetCard(ctx context.Context, token, cardID string) (*Card, error) {
+	var card *Card
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.GetContext(ctx, fmt.Sprintf("cards/%s", cardID), nil, &card)
+	if err != nil {
+		log.Warningf("Failed to fetch uphold card %s on behalf of client id: %s; %s", cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 {
+		log.Debugf("Fetched uphold card %s on behalf of client id: %s", cardID, c.config.ClientID)
+		return card, nil
+	}
+
+	return nil, fmt.Errorf("Failed to fetch uphold card %s; status: %d", cardID, status)
+}
+
+// CreateCard creates a card holding the given currency for the given bearer token
+func (c *Client) CreateCard(ctx context.Context, token, label, currency string) (*Card, error) {
+	var card *Card
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.PostContext(ctx, "cards", map[string]interface{}{
+		"label":    label,
+		"currency": currency,
+	}, &card)
+	if err != nil {
+		log.Warningf("Failed to create uphold %s card on behalf of client id: %s; %s", currency, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 || status == 201 {
+		log.Debugf("Created uphold %s card on behalf of client id: %s", currency, c.config.ClientID)
+		return card, nil
+	}
+
+	return nil, fmt.Errorf("Failed to create uphold %s card; status: %d", currency, status)
+}
+
+// UpdateCard updates the label and/or settings of the card with the given id for the given bearer token;
+// a *ValidationError is returned if the update is nil or does not change any property
+func (c *Client) UpdateCard(ctx context.Context, token, cardID string, update *CardUpdate) (*Card, error) {
+	var card *Card
+	var err error
+
+	if err := update.Validate(); err != nil {
+		log.Warningf("Failed to validate uphold card %s update on behalf of client id: %s; %s", cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{}
+	if update.Label != nil {
+		params["label"] = *update.Label
+	}
+	if update.Settings != nil {
+		params["settings"] = update.Settings
+	}
+
+	status, err := client.PatchContext(ctx, fmt.Sprintf("cards/%s", cardID), params, &card)
+	if err != nil {
+		log.Warningf("Failed to update uphold card %s on behalf of client id: %s; %s", cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 {
+		log.Debugf("Updated uphold card %s on behalf of client id: %s", cardID, c.config.ClientID)
+		return card, nil
+	}
+
+	return nil, fmt.Errorf("Failed to update uphold card %s; status: %d", cardID, status)
+}
+
+// Networks supported for crypto deposit addresses
+const (
+	NetworkBitcoin     = "bitcoin"
+	NetworkBitcoinCash = "bitcoin-cash"
+	NetworkEthereum    = "ethereum"
+	NetworkLitecoin    = "litecoin"
+	NetworkDash        = "dash"
+	NetworkXRPLedger   = "xrp-ledger"
+	NetworkStellar     = "stellar"
+)
+
+// qrPayloadSchemes maps networks to the URI scheme used to encode deposit addresses as QR payloads
+var qrPayloadSchemes = map[string]string{
+	NetworkBitcoin:     "bitcoin",
+	NetworkBitcoinCash: "bitcoincash",
+	NetworkEthereum:    "ethereum",
+	NetworkLitecoin:    "litecoin",
+	NetworkDash:        "dash",
+	NetworkXRPLedger:   "ripple",
+}
+
+// CreateCardAddress generates a crypto deposit address on the given network for the card with the given id
+func (c *Client) CreateCardAddress(ctx context.Context, token, cardID, network string) (*CardAddress, error) {
+	var address *CardAddress
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.PostContext(ctx, fmt.Sprintf("cards/%s/addresses", cardID), map[string]interface{}{
+		"network": network,
+	}, &address)
+	if err != nil {
+		log.Warningf("Failed to create %s address for uphold card %s on behalf of client id: %s; %s", network, cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 || status == 201 {
+		log.Debugf("Created %s address for uphold card %s on behalf of client id: %s", network, cardID, c.config.ClientID)
+		return address, nil
+	}
+
+	return nil, fmt.Errorf("Failed to create %s address for uphold card %s; status: %d", network, cardID, status)
+}
+
+// ListCardAddresses fetches the crypto deposit addresses of the card with the given id
+func (c *Client) ListCardAddresses(ctx context.Context, token, cardID string) ([]*CardAddress, error) {
+	var addresses []*CardAddress
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeCardsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.GetContext(ctx, fmt.Sprintf("cards/%s/addresses", cardID), nil, &addresses)
+	if err != nil {
+		log.Warningf("Failed to list addresses of uphold card %s on behalf of client id: %s; %s", cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 {
+		log.Debugf("Fetched %d address(es) of uphold card %s on behalf of client id: %s", len(addresses), cardID, c.config.ClientID)
+		return addresses, nil
+	}
+
+	return nil, fmt.Errorf("Failed to list addresses of uphold card %s; status: %d", cardID, status)
+}
+
+// Address returns the deposit address, regardless of whether it was created or listed
+func (a *CardAddress) Address() string {
+	if a.ID != nil {
+		return *a.ID
+	}
+	if len(a.Formats) > 0 {
+		return a.Formats[0].Value
+	}
+	return ""
+}
+
+// NetworkName returns the network of the deposit address, regardless of whether it was created or listed
+func (a *CardAddress) NetworkName() string {
+	if a.Network != nil {
+		return *a.Network
+	}
+	if a.Type != nil {
+		return *a.Type
+	}
+	return ""
+}
+
+// QRPayload returns the payment URI, e.g., bitcoin:<address>, which should be encoded when rendering the
+// deposit address as a QR code; the destination tag, if any, is included as the dt query parameter.
+// The bare address is returned for networks without a well-known URI scheme.
+func (a *CardAddress) QRPayload() string {
+	payload := a.Address()
+	if scheme, schemeOk := qrPayloadSchemes[a.NetworkName()]; schemeOk {
+		payload = fmt.Sprintf("%s:%s", scheme, payload)
+	}
+	if a.Tag != nil && *a.Tag != "" {
+		payload = fmt.Sprintf("%s?dt=%s", payload, url.QueryEscape(*a.Tag))
+	}
+	return payload
+}
@@ -0,0 +1,212 @@
+package uphold
+
+import (
+	"net/url"
+	"sync"
+)
+
+var (
+	defaultClientOnce sync.Once
+	defaultClientErr  error
+	defaultClient     *Client
+)
+
+// Client calls the uphold API on behalf of the OAuth application described by its Config
+type Client struct {
+	config      Config
+	apiURL      *url.URL
+	options     []APIClientOption
+	rateLimiter *RateLimiter
+	tokenSource TokenSource
+	tokenStore  TokenStore
+	userLocks   *sync.Map
+
+	// unpersistedTokens holds refreshed user tokens which the TokenStore failed to persist, keyed by user id
+	unpersistedTokens *sync.Map
+
+	applicationTokens *applicationTokenCache
+}
+
+// NewClient initializes a Client for the given Config; the given options are applied to every
+// APIClient constructed by the Client. Unless overridden using WithRateLimiter, the APIClient
+// instances constructed by the Client share a RateLimiter which tracks the uphold quota.
+func NewClient(config *Config, opts ...APIClientOption) (*Client, error) {
+	apiURL, err := url.Parse(config.APIBaseURL)
+	if err != nil {
+		log.Warningf("Failed to parse uphold API base url; %s", err.Error())
+		return nil, err
+	}
+
+	// resolve the RateLimiter shared by the constructed APIClient instances, which may be overridden by the given options
+	probe := &APIClient{RateLimiter: NewRateLimiter()}
+	for _, opt := range opts {
+		opt(probe)
+	}
+
+	return &Client{
+		config:      *config,
+		apiURL:      apiURL,
+		options:     opts,
+		rateLimiter: probe.RateLimiter,
+		userLocks:   &sync.Map{},
+
+		unpersistedTokens: &sync.Map{},
+		applicationTokens: newApplicationTokenCache(),
+	}, nil
+}
+
+// DefaultClient returns the package-wide Client configured using ConfigFromEnv
+func DefaultClient() (*Client, error) {
+	defaultClientOnce.Do(func() {
+		defaultClient, defaultClientErr = NewClient(ConfigFromEnv())
+	})
+	return defaultClient, defaultClientErr
+}
+
+// Config returns a copy of the Config used by the Client
+func (c *Client) Config() Config {
+	return c.config
+}
+
+// RateLimit returns the uphold request quota most recently reported to the Client, or nil if none has been reported
+func (c *Client) RateLimit() *RateLimit {
+	if c.rateLimiter == nil {
+		return nil
+	}
+	return c.rateLimiter.RateLimit()
+}
+
+// NewAPIClient initializes an APIClient using the configured client id and secret to construct
+// an HTTP basic authorization header, unless a non-nil bearer access token is provided.
+func (c *Client) NewAPIClient(token, baseURI *string, opts ...APIClientOption) (*APIClient, error) {
+	client := c.newAPIClient(baseURI)
+	if token != nil {
+		client.Token = token
+	} else {
+		client.Username = stringOrNil(c.config.ClientID)
+		client.Password = stringOrNil(c.config.ClientSecret)
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client, nil
+}
+
+// NewBearerAPIClient initializes an APIClient authorized using the given bearer access token; when the token is
+// empty and the Client is bound to a TokenSource, the access token is resolved, and refreshed if necessary, using it
+func (c *Client) NewBearerAPIClient(token, baseURI string, opts ...APIClientOption) (*APIClient, error) {
+	client, err := c.bearerAPIClient(token, baseURI)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client, nil
+}
+
+// bearerAPIClient initializes an APIClient as NewBearerAPIClient does; when the scopes granted to the token are known,
+// a *ScopeError is returned if they lack any of the required scopes
+func (c *Client) bearerAPIClient(token, baseURI string, required ...Scope) (*APIClient, error) {
+	token, granted, err := c.resolveBearerToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if granted != nil {
+		if missing := granted.Missing(required...); len(missing) > 0 {
+			return nil, c.scopeError(&ScopeError{Required: NewScopes(required...), Missing: missing, Granted: granted})
+		}
+	}
+
+	return c.NewAPIClient(stringOrNil(token), stringOrNil(baseURI))
+}
+
+// bearerAPIClientAny initializes an APIClient as bearerAPIClient does, but requires only one of the given scopes
+func (c *Client) bearerAPIClientAny(token, baseURI string, scopes ...Scope) (*APIClient, error) {
+	token, granted, err := c.resolveBearerToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if granted != nil && !granted.ContainsAny(scopes...) {
+		required := NewScopes(scopes...)
+		return nil, c.scopeError(&ScopeError{Required: required, Missing: required, Granted: granted, Any: true})
+	}
+
+	return c.NewAPIClient(stringOrNil(token), stringOrNil(baseURI))
+}
+
+// resolveBearerToken returns the given bearer token or, when it is empty and the Client is bound to a TokenSource,
+// the access token provided by the TokenSource; the scopes granted to the token are nil unless known
+func (c *Client) resolveBearerToken(token string) (string, Scopes, error) {
+	if token != "" || c.tokenSource == nil {
+		return token, nil, nil
+	}
+
+	t, err := c.tokenSource.Token()
+	if err != nil {
+		log.Warningf("Failed to resolve uphold bearer token on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return "", nil, err
+	}
+
+	if t.Scope == "" {
+		return t.AccessToken, nil, nil
+	}
+	return t.AccessToken, ParseScopes(t.Scope), nil
+}
+
+func (c *Client) scopeError(err *ScopeError) error {
+	log.Warningf("Refusing to invoke uphold API on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+	return err
+}
+
+// WithTokenSource returns a copy of the Client bound to the given TokenSource; bearer token parameters may be
+// passed as empty strings to methods of the returned Client, in which case the TokenSource provides the token
+func (c *Client) WithTokenSource(source TokenSource) *Client {
+	client := *c
+	client.tokenSource = source
+	return &client
+}
+
+// WithToken returns a copy of the Client bound to the given token, which is never refreshed; bearer token parameters
+// may be passed as empty strings to methods of the returned Client, which verify the token's Scope, if set, before
+// sending requests. Use it to have the scopes granted to a raw access token checked.
+func (c *Client) WithToken(token *Token) *Client {
+	return c.WithTokenSource(StaticTokenSource(token))
+}
+
+// NewUnauthorizedAPIClient initializes an APIClient without API credentials
+func (c *Client) NewUnauthorizedAPIClient(baseURI *string, opts ...APIClientOption) (*APIClient, error) {
+	client := c.newAPIClient(baseURI)
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client, nil
+}
+
+func (c *Client) newAPIClient(baseURI *string) *APIClient {
+	path := ""
+	if baseURI != nil {
+		path = *baseURI
+	}
+
+	client := &APIClient{
+		Host:        c.apiURL.Host,
+		Scheme:      c.apiURL.Scheme,
+		Path:        path,
+		RateLimiter: c.rateLimiter,
+	}
+
+	for _, opt := range c.options {
+		opt(client)
+	}
+
+	return client
+}
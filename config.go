@@ -0,0 +1,52 @@
+package uphold
+
+import (
+	"os"
+)
+
+const upholdProductionBaseURL = "https://uphold.com"
+const upholdProductionAPIBaseURL = "https://api.uphold.com"
+
+// Config describes the uphold environment and OAuth application used by a Client
+type Config struct {
+	BaseURL      string // the uphold webapp base url, used to build authorization urls
+	APIBaseURL   string // the uphold API base url
+	ClientID     string // the OAuth application client id
+	ClientSecret string // the OAuth application client secret
+}
+
+// NewSandboxConfig returns a Config for the given OAuth application in the uphold sandbox environment
+func NewSandboxConfig(clientID, clientSecret string) *Config {
+	return &Config{
+		BaseURL:      upholdSandboxBaseURL,
+		APIBaseURL:   upholdSandboxAPIBaseURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// NewProductionConfig returns a Config for the given OAuth application in the uphold production environment
+func NewProductionConfig(clientID, clientSecret string) *Config {
+	return &Config{
+		BaseURL:      upholdProductionBaseURL,
+		APIBaseURL:   upholdProductionAPIBaseURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	}
+}
+
+// ConfigFromEnv returns a Config read from the UPHOLD_BASE_URL, UPHOLD_API_BASE_URL, UPHOLD_CLIENT_ID
+// and UPHOLD_CLIENT_SECRET environment variables; the base urls default to the uphold sandbox environment
+func ConfigFromEnv() *Config {
+	config := NewSandboxConfig(os.Getenv("UPHOLD_CLIENT_ID"), os.Getenv("UPHOLD_CLIENT_SECRET"))
+
+	if os.Getenv("UPHOLD_BASE_URL") != "" {
+		config.BaseURL = os.Getenv("UPHOLD_BASE_URL")
+	}
+
+	if os.Getenv("UPHOLD_API_BASE_URL") != "" {
+		config.APIBaseURL = os.Getenv("UPHOLD_API_BASE_URL")
+	}
+
+	return config
+}
@@ -0,0 +1,240 @@
+package uphold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const errorCodeNotFound = "not_found"
+const errorCodeOTPRequired = "otp_required"
+const errorCodeValidationFailed = "validation_failed"
+
+// insufficientBalanceErrorCodes are the error codes used by uphold to reject an operation
+// which would overdraw a card
+var insufficientBalanceErrorCodes = map[string]bool{
+	"insufficient_balance": true,
+	"sufficient_funds":     true,
+}
+
+// Error is returned when the uphold API responds with a non-2xx status code; validation errors are
+// reported by uphold as a (possibly nested) map of field names to error descriptions
+type Error struct {
+	StatusCode int                    `json:"-"`       // the HTTP status code of the response
+	Code       string                 `json:"code"`    // the uphold error code, e.g., validation_failed
+	Message    string                 `json:"message"` // the human readable error message, if any
+	Errors     map[string]interface{} `json:"errors"`  // the field errors, keyed by field name
+	RequestID  string                 `json:"-"`       // the request id assigned by uphold, if any
+	Header     http.Header            `json:"-"`       // the headers of the response
+
+	Method string `json:"-"` // the method of the failed request
+	URL    string `json:"-"` // the url of the failed request
+	Body   []byte `json:"-"` // the raw response body
+}
+
+// FieldError describes a single validation error for a field
+type FieldError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when request parameters are rejected client-side, before a request is sent
+type ValidationError struct {
+	Field   string // the name of the invalid field, e.g., denomination.amount
+	Message string // the reason the field is invalid
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s; %s", e.Field, e.Message)
+}
+
+// transactionStateStatusCodes are the HTTP status codes with which uphold rejects an operation
+// which is not permitted in the current state of a transaction; other 4xx responses, e.g., 400
+// validation or authorization failures, are not related to the state of the transaction
+var transactionStateStatusCodes = map[int]bool{
+	http.StatusConflict:            true,
+	http.StatusUnprocessableEntity: true,
+}
+
+// TransactionStateError is returned when an operation, e.g., cancel, is not permitted in the current state of a transaction
+type TransactionStateError struct {
+	TransactionID string // the id of the transaction
+	Operation     string // the rejected operation, e.g., cancel or resend
+	APIError      *Error // the underlying API error
+}
+
+// Error implements the error interface
+func (e *TransactionStateError) Error() string {
+	return fmt.Sprintf("cannot %s uphold transaction %s in its current state; %s", e.Operation, e.TransactionID, e.APIError.Error())
+}
+
+// Unwrap returns the underlying *Error
+func (e *TransactionStateError) Unwrap() error {
+	return e.APIError
+}
+
+// newError builds an *Error for the given non-2xx response and raw body
+func newError(method, urlString string, resp *http.Response, body []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Method:     method,
+		URL:        urlString,
+		Body:       body,
+	}
+
+	if resp.Header.Get("X-Request-Id") != "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-Id")
+	} else {
+		apiErr.RequestID = resp.Header.Get("Request-Id")
+	}
+
+	if len(body) > 0 {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if err := json.Unmarshal(body, apiErr); err != nil {
+			log.Debugf("Failed to unmarshal uphold API (%s %s) error response: %s; %s", method, urlString, body, err.Error())
+		} else if apiErr.Code == "" && json.Unmarshal(body, &oauthErr) == nil {
+			apiErr.Code = oauthErr.Error
+			apiErr.Message = oauthErr.ErrorDescription
+		}
+	}
+
+	if apiErr.Code == "" && resp.StatusCode == http.StatusNotFound {
+		apiErr.Code = errorCodeNotFound
+	}
+
+	return apiErr
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("uphold API (%s %s) returned status code: %d", e.Method, e.URL, e.StatusCode)
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s; code: %s", msg, e.Code)
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s; %s", msg, e.Message)
+	}
+	if fieldErrs := e.FieldErrors(); len(fieldErrs) > 0 {
+		fields := make([]string, 0, len(fieldErrs))
+		for field, errs := range fieldErrs {
+			codes := make([]string, 0, len(errs))
+			for _, fieldErr := range errs {
+				codes = append(codes, fieldErr.Code)
+			}
+			fields = append(fields, fmt.Sprintf("%s: %s", field, strings.Join(codes, ", ")))
+		}
+		sort.Strings(fields)
+		msg = fmt.Sprintf("%s; %s", msg, strings.Join(fields, "; "))
+	}
+	return msg
+}
+
+// FieldErrors flattens the nested field errors returned by uphold, keyed by dotted field path, e.g., denomination.amount
+func (e *Error) FieldErrors() map[string][]*FieldError {
+	fieldErrs := map[string][]*FieldError{}
+	flattenFieldErrors("", e.Errors, fieldErrs)
+	return fieldErrs
+}
+
+// HasCode returns true if the error, or any of its field errors, carries the given uphold error code
+func (e *Error) HasCode(code string) bool {
+	if e.Code == code {
+		return true
+	}
+	for _, errs := range e.FieldErrors() {
+		for _, fieldErr := range errs {
+			if fieldErr.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func flattenFieldErrors(prefix string, errs map[string]interface{}, fieldErrs map[string][]*FieldError) {
+	for name, val := range errs {
+		path := name
+		if prefix != "" {
+			path = fmt.Sprintf("%s.%s", prefix, name)
+		}
+
+		switch v := val.(type) {
+		case []interface{}:
+			for _, item := range v {
+				if desc, descOk := item.(map[string]interface{}); descOk {
+					fieldErrs[path] = append(fieldErrs[path], fieldErrorFromMap(desc))
+				}
+			}
+		case map[string]interface{}:
+			if nested, nestedOk := v["errors"].(map[string]interface{}); nestedOk {
+				flattenFieldErrors(path, nested, fieldErrs)
+			} else {
+				fieldErrs[path] = append(fieldErrs[path], fieldErrorFromMap(v))
+			}
+		}
+	}
+}
+
+func fieldErrorFromMap(desc map[string]interface{}) *FieldError {
+	fieldErr := &FieldError{}
+	if code, codeOk := desc["code"].(string); codeOk {
+		fieldErr.Code = code
+	}
+	if message, messageOk := desc["message"].(string); messageOk {
+		fieldErr.Message = message
+	}
+	return fieldErr
+}
+
+// AsError returns the *Error wrapped by err, if any
+func AsError(err error) (*Error, bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound returns true if err was returned because the requested uphold resource does not exist
+func IsNotFound(err error) bool {
+	apiErr, ok := AsError(err)
+	return ok && (apiErr.StatusCode == http.StatusNotFound || apiErr.Code == errorCodeNotFound)
+}
+
+// IsValidationFailed returns true if err was returned because the request parameters were rejected, either client-side or by uphold
+func IsValidationFailed(err error) bool {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return true
+	}
+	apiErr, ok := AsError(err)
+	return ok && apiErr.Code == errorCodeValidationFailed
+}
+
+// IsInvalidTransactionState returns true if err was returned because an operation is not permitted in the current state of a transaction
+func IsInvalidTransactionState(err error) bool {
+	var stateErr *TransactionStateError
+	return errors.As(err, &stateErr)
+}
+
+// IsInsufficientBalance returns true if err was returned because the origin card lacks the funds for the request
+func IsInsufficientBalance(err error) bool {
+	apiErr, ok := AsError(err)
+	if !ok {
+		return false
+	}
+	for code := range insufficientBalanceErrorCodes {
+		if apiErr.HasCode(code) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,96 +2,315 @@ package uphold
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	uuid "github.com/kthomas/go.uuid"
 )
 
+// TransactionStatus is the status of an uphold transaction; statuses unknown to this package are preserved
+type TransactionStatus string
+
+// TransactionType is the nature of an uphold transaction; types unknown to this package are preserved
+type TransactionType string
+
+// TransactionPriority is the priority of an uphold transaction; priorities unknown to this package are preserved
+type TransactionPriority string
+
+// EndpointType is the type of the origin or destination of an uphold transaction; types unknown to this package are preserved
+type EndpointType string
+
+// Transaction statuses
+const (
+	TxStatusPending   TransactionStatus = "pending"
+	TxStatusWaiting   TransactionStatus = "waiting"
+	TxStatusCancelled TransactionStatus = "cancelled"
+	TxStatusCompleted TransactionStatus = "completed"
+	TxStatusFailed    TransactionStatus = "failed"
+)
+
+// Transaction types
+const (
+	TxTypeDeposit    TransactionType = "deposit"
+	TxTypeTransfer   TransactionType = "transfer"
+	TxTypeWithdrawal TransactionType = "withdrawal"
+)
+
+// Transaction priorities
+const (
+	TxPriorityNormal TransactionPriority = "normal"
+	TxPriorityFast   TransactionPriority = "fast"
+)
+
+// Transaction endpoint types
+const (
+	EndpointEmail    EndpointType = "email"
+	EndpointCard     EndpointType = "card"
+	EndpointExternal EndpointType = "external"
+)
+
+// IsKnown returns true if the status is one of the statuses defined by this package
+func (s TransactionStatus) IsKnown() bool {
+	switch s {
+	case TxStatusPending, TxStatusWaiting, TxStatusCancelled, TxStatusCompleted, TxStatusFailed:
+		return true
+	}
+	return false
+}
+
+// IsFinal returns true if no further status transition occurs from the status
+func (s TransactionStatus) IsFinal() bool {
+	return s == TxStatusCancelled || s == TxStatusCompleted || s == TxStatusFailed
+}
+
+// IsKnown returns true if the type is one of the types defined by this package
+func (t TransactionType) IsKnown() bool {
+	return t == TxTypeDeposit || t == TxTypeTransfer || t == TxTypeWithdrawal
+}
+
+// IsKnown returns true if the priority is one of the priorities defined by this package
+func (p TransactionPriority) IsKnown() bool {
+	return p == TxPriorityNormal || p == TxPriorityFast
+}
+
+// IsKnown returns true if the endpoint type is one of the types defined by this package
+func (t EndpointType) IsKnown() bool {
+	return t == EndpointEmail || t == EndpointCard || t == EndpointExternal
+}
+
 // OAuthResponse is the API response returned when an authorization code has been successfully upgraded to an access token
 type OAuthResponse struct {
-	AccessToken  *string `json:"access_token"`
-	ExpiresIn    *string `json:"expires_in"`
-	TokenType    *string `json:"token_type"`
-	RefreshToken *string `json:"refresh_token"`
-	Scope        *string `json:"scope"`
+	AccessToken  *string      `json:"access_token"`
+	ExpiresIn    *json.Number `json:"expires_in"` // the lifetime of the access token in seconds
+	TokenType    *string      `json:"token_type"`
+	RefreshToken *string      `json:"refresh_token"`
+	Scope        *string      `json:"scope"`
+	ExpiresAt    *time.Time   `json:"-"` // the time at which the access token expires, resolved from ExpiresIn when the response is received
+}
+
+// Card represents an uphold card, which holds a balance in a single currency
+type Card struct {
+	ID                *string                  `json:"id"`                // a unique ID associated with the card.
+	Address           map[string]string        `json:"address"`           // the deposit addresses of the card, keyed by network.
+	Available         *Amount                  `json:"available"`         // the balance available for withdrawal or usage.
+	Balance           *Amount                  `json:"balance"`           // the total balance of the card, including all pending transactions.
+	Currency          string                   `json:"currency"`          // the currency of the card.
+	Label             *string                  `json:"label"`             // the display name of the card as chosen by the user.
+	LastTransactionAt *time.Time               `json:"lastTransactionAt"` // the date and time of the most recent transaction.
+	Settings          *CardSettings            `json:"settings"`          // the display settings of the card.
+	Normalized        []*CardNormalizedBalance `json:"normalized"`        // the balances of the card in the user's preferred currency.
+}
+
+// CardSettings describes how an uphold card is displayed
+type CardSettings struct {
+	Position  *int  `json:"position,omitempty"`  // the position of the card in the user's card list.
+	Protected *bool `json:"protected,omitempty"` // a boolean signaling if the card is protected against transfers.
+	Starred   *bool `json:"starred,omitempty"`   // a boolean signaling if the card is starred.
+}
+
+// CardNormalizedBalance contains the balances of a card expressed in another currency
+type CardNormalizedBalance struct {
+	Available *Amount `json:"available"` // the available balance.
+	Balance   *Amount `json:"balance"`   // the total balance.
+	Currency  string  `json:"currency"`  // the currency in which the balances are expressed.
+}
+
+// CardAddress represents a crypto deposit address of an uphold card; addresses returned when
+// listing a card's addresses carry their network as the type and the address within formats
+type CardAddress struct {
+	ID      *string              `json:"id"`      // the address.
+	Network *string              `json:"network"` // the network of the address, e.g., bitcoin.
+	Tag     *string              `json:"tag"`     // the destination tag or memo which must accompany deposits on some networks, e.g., xrp-ledger.
+	Type    *string              `json:"type"`    // the network of the address, as returned when listing a card's addresses.
+	Formats []*CardAddressFormat `json:"formats"` // the formats in which the address may be expressed, as returned when listing a card's addresses.
+}
+
+// CardAddressFormat describes a single format of a crypto deposit address
+type CardAddressFormat struct {
+	Format string `json:"format"` // the name of the format, e.g., pubkeyhash.
+	Value  string `json:"value"`  // the address expressed in the format.
 }
 
 // Denomination describes the value being transacted, in terms of a specific currency
 type Denomination struct {
-	Amount   float64 `json:"amount"`
-	Currency string  `json:"currency"`
-	Pair     *string `json:"pair"`
-	Rate     *string `json:"rate"`
+	Amount   *Amount `json:"amount"`   // the amount to be transferred.
+	Currency string  `json:"currency"` // the currency of the amount.
+	Pair     *string `json:"pair"`     // the currency pair for conversion between origin and destination, e.g., BTCUSD.
+	Rate     *string `json:"rate"`     // the quoted rate for converting between origin and destination.
+	Target   *string `json:"target"`   // can be origin or destination and determines where the amount is denominated.
 }
 
 // Fee describes an applied transaction fee
 type Fee struct {
-	Amount     float64 `json:"amount"`
-	Currency   string  `json:"currency"`
-	Percentage *string `json:"percentage"`
-	Target     *string `json:"target"`
-	Type       *string `json:"type"`
+	Amount     *Amount `json:"amount"`     // the amount of the fee.
+	Currency   string  `json:"currency"`   // the currency of the fee.
+	Percentage *string `json:"percentage"` // the percentage of the transaction amount charged as the fee.
+	Target     *string `json:"target"`     // can be origin or destination and determines where the fee was applied.
+	Type       *string `json:"type"`       // the type of fee, e.g., exchange or network.
 }
 
-// Destination contains properites regarding how the transaction affects the destination of the funds
+// Destination contains properites regarding how the transaction affects the destination of the funds;
+// uphold sends the card id as cardId, or as CardId in some payloads; either is decoded, and the card id
+// is encoded again using the field name with which it was received
 type Destination struct {
-	CardID      string  `json:"CardId"`      // the ID of the card credited. Only visible to the user who receives the transaction.
-	Amount      float64 `json:"amount"`      // the amount credited, including commissions and fees.
-	Base        float64 `json:"base"`        // the amount to credit, before commissions or fees.
-	Comission   float64 `json:"comission"`   // the commission charged by Uphold to process the transaction. Commissions are only charged when currency is converted into a different denomination.
-	Currency    string  `json:"currency"`    // the denomination of the funds at the time they were sent/received.
-	Description *string `json:"description"` // the name of the recipient. In the case where money is sent via email, the description will contain the email address of the recipient.
-	Fee         float64 `json:"fee"`         // the Bitcoin network Fee, if destination is a BTC address but origin is not.
-	IsMember    *bool   `json:"isMember"`    // a boolean signaling if the destination user has completed the membership process.
-	Node        *string `json:"node"`        // the details about the transaction destination node.
-	Rate        *string `json:"rate"`        // the rate for conversion between origin and destination, as expressed in the currency at destination (the inverse of origin.rate).
-	Type        *string `json:"type"`        //	the type of endpoint. Possible values are 'email’, 'card’ and 'external’.
-}
-
-// Origin contains properties regarding how the transaction affects the origin of the funds
+	AccountID   *string                `json:"accountId"`          // the ID of the account credited, if any.
+	AccountType *string                `json:"accountType"`        // the type of the account credited, if any.
+	CardID      string                 `json:"cardId,omitempty"`   // the ID of the card credited. Only visible to the user who receives the transaction.
+	Amount      *Amount                `json:"amount"`             // the amount credited, including commissions and fees.
+	Base        *Amount                `json:"base"`               // the amount to credit, before commissions or fees.
+	Commission  *Amount                `json:"commission"`         // the commission charged by Uphold to process the transaction. Commissions are only charged when currency is converted into a different denomination.
+	Currency    string                 `json:"currency,omitempty"` // the denomination of the funds at the time they were sent/received.
+	Description *string                `json:"description"`        // the name of the recipient. In the case where money is sent via email, the description will contain the email address of the recipient.
+	Fee         *Amount                `json:"fee"`                // the Bitcoin network Fee, if destination is a BTC address but origin is not.
+	IsMember    *bool                  `json:"isMember"`           // a boolean signaling if the destination user has completed the membership process.
+	Node        map[string]interface{} `json:"node"`               // the details about the transaction destination node.
+	Rate        *string                `json:"rate"`               // the rate for conversion between origin and destination, as expressed in the currency at destination (the inverse of origin.rate).
+	Type        *EndpointType          `json:"type"`               //	the type of endpoint. Possible values are 'email’, 'card’ and 'external’.
+
+	cardIDKey string // the field name with which uphold sent the card id, if not cardId
+}
+
+// Origin contains properties regarding how the transaction affects the origin of the funds; as with
+// Destination, the card id is decoded from either cardId or CardId and encoded using the same field name
 type Origin struct {
-	CardID      string              `json:"CardId"`      // the ID of the card debited. Only visible to the user who sends the transaction.
-	Amount      float64             `json:"amount"`      // the amount debited, including commissions and fees.
-	Base        float64             `json:"base"`        // the amount to debit, before commissions or fees.
-	Comission   float64             `json:"comission"`   // the commission charged by Uphold to process the transaction.
-	Currency    string              `json:"currency"`    // the currency of the funds at the origin.
-	Description *string             `json:"description"` // the name of the sender.
-	Fee         float64             `json:"fee"`         // the Bitcoin network Fee, if origin is in BTC but destination is not, or is a non-Uphold Bitcoin Address.
-	IsMember    *bool               `json:"isMember"`    // a boolean signaling if the origin user has completed the membership process.
-	Node        *string             `json:"node"`        // the details about the transaction origin node.
-	Rate        *string             `json:"rate"`        // the rate for conversion between origin and destination, as expressed in the currency at origin (the inverse of destination.rate).
-	Type        *string             `json:"type"`        //	the type of endpoint. Possible values are 'card’ and 'external’.
-	Sources     []map[string]string `json:"sources"`     // the transactions where the value was originated from (id and amount).
-	Username    string              `json:"username"`    // the username from the user that performed the transaction.
+	AccountID   *string                `json:"accountId"`          // the ID of the account debited, if any.
+	AccountType *string                `json:"accountType"`        // the type of the account debited, if any.
+	CardID      string                 `json:"cardId,omitempty"`   // the ID of the card debited. Only visible to the user who sends the transaction.
+	Amount      *Amount                `json:"amount"`             // the amount debited, including commissions and fees.
+	Base        *Amount                `json:"base"`               // the amount to debit, before commissions or fees.
+	Commission  *Amount                `json:"commission"`         // the commission charged by Uphold to process the transaction.
+	Currency    string                 `json:"currency,omitempty"` // the currency of the funds at the origin.
+	Description *string                `json:"description"`        // the name of the sender.
+	Fee         *Amount                `json:"fee"`                // the Bitcoin network Fee, if origin is in BTC but destination is not, or is a non-Uphold Bitcoin Address.
+	IsMember    *bool                  `json:"isMember"`           // a boolean signaling if the origin user has completed the membership process.
+	Node        map[string]interface{} `json:"node"`               // the details about the transaction origin node.
+	Rate        *string                `json:"rate"`               // the rate for conversion between origin and destination, as expressed in the currency at origin (the inverse of destination.rate).
+	Type        *EndpointType          `json:"type"`               //	the type of endpoint. Possible values are 'card’ and 'external’.
+	Sources     []map[string]string    `json:"sources"`            // the transactions where the value was originated from (id and amount).
+	Username    string                 `json:"username,omitempty"` // the username from the user that performed the transaction.
+
+	cardIDKey string // the field name with which uphold sent the card id, if not cardId
+}
+
+// UnmarshalJSON decodes the destination, remembering the field name with which the card id was sent
+func (d *Destination) UnmarshalJSON(data []byte) error {
+	type destination Destination
+	var dest destination
+	if err := json.Unmarshal(data, &dest); err != nil {
+		return err
+	}
+	*d = Destination(dest)
+	d.cardIDKey = cardIDKey(data)
+	return nil
+}
+
+// MarshalJSON encodes the destination, sending the card id using the field name with which it was received
+func (d Destination) MarshalJSON() ([]byte, error) {
+	type destination Destination
+	return marshalWithCardIDKey(destination(d), d.cardIDKey)
+}
+
+// UnmarshalJSON decodes the origin, remembering the field name with which the card id was sent
+func (o *Origin) UnmarshalJSON(data []byte) error {
+	type origin Origin
+	var orig origin
+	if err := json.Unmarshal(data, &orig); err != nil {
+		return err
+	}
+	*o = Origin(orig)
+	o.cardIDKey = cardIDKey(data)
+	return nil
+}
+
+// MarshalJSON encodes the origin, sending the card id using the field name with which it was received
+func (o Origin) MarshalJSON() ([]byte, error) {
+	type origin Origin
+	return marshalWithCardIDKey(origin(o), o.cardIDKey)
+}
+
+// cardIDKey returns the field name with which the card id was sent in the given origin or destination
+// payload, or an empty string if it was sent as cardId or not at all
+func cardIDKey(data []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	if _, ok := fields["cardId"]; ok {
+		return ""
+	}
+	for key := range fields {
+		if strings.EqualFold(key, "cardId") {
+			return key
+		}
+	}
+	return ""
+}
+
+// marshalWithCardIDKey encodes the given origin or destination, renaming cardId to the given key, if any
+func marshalWithCardIDKey(v interface{}, key string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || key == "" {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if cardID, ok := fields["cardId"]; ok {
+		delete(fields, "cardId")
+		fields[key] = cardID
+	}
+	return json.Marshal(fields)
 }
 
 // Normalized tx property contains the normalized amount and commission values in USD
 type Normalized struct {
-	Amount    float64 `json:"amount"`    // the amount to be transacted.
-	Comission float64 `json:"comission"` // the total commission taken on this transaction, either at origin or at destination.
-	Currency  string  `json:"currency"`  // the currency in which the amount and commission are expressed. The value is always USD.
-	Fee       float64 `json:"fee"`       // the normalized fee amount.
-	Rate      *string `json:"rate"`      // the exchange rate for this pair.
-	Target    *string `json:"type"`      //	can be origin or destination and determines where the fee was applied.
+	Amount     *Amount `json:"amount"`     // the amount to be transacted.
+	Commission *Amount `json:"commission"` // the total commission taken on this transaction, either at origin or at destination.
+	Currency   string  `json:"currency"`   // the currency in which the amount and commission are expressed. The value is always USD.
+	Fee        *Amount `json:"fee"`        // the normalized fee amount.
+	Rate       *string `json:"rate"`       // the exchange rate for this pair.
+	Target     *string `json:"target"`     //	can be origin or destination and determines where the fee was applied.
 }
 
 // Transaction represents an uphold card transaction
 type Transaction struct {
-	ID           *uuid.UUID       `json:"id"`           // a unique ID on the Uphold Network associated with the transaction.
-	CreatedAt    *time.Time       `json:"createdAt"`    // the date and time the transaction was initiated.
-	Application  *string          `json:"application"`  // the application that created the transaction.
-	Denomination *Denomination    `json:"denomination"` // a message or note provided by the user at the time the transaction was initiated, with the intent of communicating additional information and context about the nature/purpose of the transaction.
-	Destination  *Destination     `json:"destination"`  // the funds to be transferred, as originally requested.
-	Origin       *Origin          `json:"origin"`       // the fees that were applied to the transaction.
-	Normalized   *Normalized      `json:"normalized"`   // the transaction details in USD.
-	Fees         []*Fee           `json:"fees"`         // the fees that were applied to the transaction.
-	Message      *string          `json:"message"`      // other parameters of this transaction.
-	Network      *string          `json:"network"`      // the network of the transaction (uphold for internal transactions).
-	Priority     *string          `json:"priority"`     // the priority of the transaction. Possible values are normal and fast.
-	Reference    *string          `json:"reference"`    // A reference assigned to the transaction.
-	Params       *json.RawMessage `json:"params"`       // other parameters of this transaction.
-	Status       *string          `json:"status"`       // the current status of the transaction. Possible values are: pending, waiting, cancelled or completed.
-	Type         *string          `json:"type"`         // the nature of the transaction. Possible values are deposit, transfer and withdrawal.
+	ID           *uuid.UUID           `json:"id"`           // a unique ID on the Uphold Network associated with the transaction.
+	CreatedAt    *time.Time           `json:"createdAt"`    // the date and time the transaction was initiated.
+	Application  *string              `json:"application"`  // the application that created the transaction.
+	Denomination *Denomination        `json:"denomination"` // the funds to be transferred, as originally requested.
+	Destination  *Destination         `json:"destination"`  // the destination of the funds.
+	Origin       *Origin              `json:"origin"`       // the origin of the funds.
+	Normalized   []*Normalized        `json:"normalized"`   // the transaction details in USD.
+	Fees         []*Fee               `json:"fees"`         // the fees that were applied to the transaction.
+	Message      *string              `json:"message"`      // a message or note provided by the user at the time the transaction was initiated.
+	Network      *string              `json:"network"`      // the network of the transaction (uphold for internal transactions).
+	Priority     *TransactionPriority `json:"priority"`     // the priority of the transaction. Possible values are normal and fast.
+	Reference    *string              `json:"reference"`    // A reference assigned to the transaction.
+	Params       *json.RawMessage     `json:"params"`       // other parameters of this transaction.
+	Status       *TransactionStatus   `json:"status"`       // the current status of the transaction. Possible values are: pending, waiting, cancelled or completed.
+	Type         *TransactionType     `json:"type"`         // the nature of the transaction. Possible values are deposit, transfer and withdrawal.
+}
+
+// IsFinal returns true if the transaction has reached a status from which no further transition occurs
+func (t *Transaction) IsFinal() bool {
+	return t.Status != nil && t.Status.IsFinal()
+}
+
+// IsPending returns true if the transaction has not yet reached a final status
+func (t *Transaction) IsPending() bool {
+	return t.Status != nil && !t.Status.IsFinal()
+}
+
+// IsOutgoing returns true if the transaction debits the card with the given id
+func (t *Transaction) IsOutgoing(cardID string) bool {
+	return t.Origin != nil && t.Origin.CardID != "" && t.Origin.CardID == cardID
+}
+
+// IsIncoming returns true if the transaction credits the card with the given id
+func (t *Transaction) IsIncoming(cardID string) bool {
+	return t.Destination != nil && t.Destination.CardID != "" && t.Destination.CardID == cardID
 }
 
 // User represents an uphold user
@@ -0,0 +1,146 @@
+package uphold
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestModelFixturesRoundTrip decodes each captured uphold payload in testdata, encodes it again and
+// requires the result to be semantically equal to the payload, so that schema drift is caught
+func TestModelFixturesRoundTrip(t *testing.T) {
+	fixtures := []struct {
+		file  string
+		model func() interface{}
+	}{
+		{"transaction_transfer.json", func() interface{} { return &Transaction{} }},
+		{"transaction_withdrawal.json", func() interface{} { return &Transaction{} }},
+		{"quote.json", func() interface{} { return &Transaction{} }},
+		{"origin_legacy_card_id.json", func() interface{} { return &Origin{} }},
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.file, func(t *testing.T) {
+			data := readFixture(t, fixture.file)
+
+			model := fixture.model()
+			if err := json.Unmarshal(data, model); err != nil {
+				t.Fatalf("failed to decode fixture; %s", err)
+			}
+			encoded, err := json.Marshal(model)
+			if err != nil {
+				t.Fatalf("failed to encode fixture; %s", err)
+			}
+
+			want := normalizeFixtureJSON(t, data)
+			got := normalizeFixtureJSON(t, encoded)
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("round trip changed fixture\nwant: %s\ngot:  %s", data, encoded)
+			}
+		})
+	}
+}
+
+func TestTransactionFixtureDecoding(t *testing.T) {
+	var transfer Transaction
+	if err := json.Unmarshal(readFixture(t, "transaction_transfer.json"), &transfer); err != nil {
+		t.Fatalf("failed to decode fixture; %s", err)
+	}
+	if transfer.Origin.CardID != "48ce2ac5-c038-4426-b2f8-a2bdbcc93053" {
+		t.Errorf("origin card id decoded from CardId = %q", transfer.Origin.CardID)
+	}
+	if transfer.Destination.CardID != "bc9b3911-4bc1-4c6d-ac05-0ae87dcfc9b3" {
+		t.Errorf("destination card id decoded from CardId = %q", transfer.Destination.CardID)
+	}
+	if !transfer.IsOutgoing("48ce2ac5-c038-4426-b2f8-a2bdbcc93053") || !transfer.IsFinal() {
+		t.Errorf("expected completed outgoing transfer")
+	}
+	if len(transfer.Normalized) != 1 || transfer.Normalized[0].Amount.String() != "6.56" || *transfer.Normalized[0].Target != "origin" {
+		t.Errorf("unexpected normalized amounts: %+v", transfer.Normalized)
+	}
+
+	var withdrawal Transaction
+	if err := json.Unmarshal(readFixture(t, "transaction_withdrawal.json"), &withdrawal); err != nil {
+		t.Fatalf("failed to decode fixture; %s", err)
+	}
+	if withdrawal.Origin.CardID != "48ce2ac5-c038-4426-b2f8-a2bdbcc93053" {
+		t.Errorf("origin card id decoded from cardId = %q", withdrawal.Origin.CardID)
+	}
+	if withdrawal.Destination.CardID != "" || *withdrawal.Destination.Type != EndpointExternal {
+		t.Errorf("expected external destination without card id, got %+v", withdrawal.Destination)
+	}
+	if len(withdrawal.Normalized) != 2 || len(withdrawal.Fees) != 1 || withdrawal.Fees[0].Amount.String() != "0.00005000" {
+		t.Errorf("unexpected normalized amounts or fees")
+	}
+	if !withdrawal.IsPending() || *withdrawal.Priority != TxPriorityFast || *withdrawal.Type != TxTypeWithdrawal {
+		t.Errorf("unexpected withdrawal status, priority or type")
+	}
+
+	var origin Origin
+	if err := json.Unmarshal(readFixture(t, "origin_legacy_card_id.json"), &origin); err != nil {
+		t.Fatalf("failed to decode fixture; %s", err)
+	}
+	if origin.CardID != "abc" || origin.Base != nil || origin.Commission != nil || origin.Fee != nil {
+		t.Errorf("expected card id and no base, commission or fee, got %+v", origin)
+	}
+}
+
+func TestQuoteFixtureTTL(t *testing.T) {
+	var tx Transaction
+	if err := json.Unmarshal(readFixture(t, "quote.json"), &tx); err != nil {
+		t.Fatalf("failed to decode fixture; %s", err)
+	}
+
+	quote := newQuote(&tx)
+	if want := tx.CreatedAt.Add(30 * time.Second); !quote.ExpiresAt.Equal(want) {
+		t.Errorf("quote expires at %s; want %s", quote.ExpiresAt, want)
+	}
+	if quote.Rate == nil || quote.Rate.String() != "0.8972" {
+		t.Errorf("quote rate = %v; want 0.8972", quote.Rate)
+	}
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s; %s", name, err)
+	}
+	return data
+}
+
+// normalizeFixtureJSON decodes the given JSON for semantic comparison; null members are treated as
+// absent and timestamps are compared as instants rather than by their textual representation
+func normalizeFixtureJSON(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("failed to decode JSON; %s", err)
+	}
+	return normalizeFixtureValue(v)
+}
+
+func normalizeFixtureValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, member := range val {
+			if member == nil {
+				delete(val, key)
+				continue
+			}
+			val[key] = normalizeFixtureValue(member)
+		}
+	case []interface{}:
+		for i, elem := range val {
+			val[i] = normalizeFixtureValue(elem)
+		}
+	case string:
+		if ts, err := time.Parse(time.RFC3339Nano, val); err == nil {
+			return ts.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return v
+}
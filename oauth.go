@@ -1,34 +1,42 @@
 package uphold
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"time"
 )
 
-// AuthorizeBearerToken synchronously authorizes a managed uphold API user using the environment-configured client id/secret and the given authorization code;
+// AuthorizeBearerToken synchronously authorizes a managed uphold API user using the configured client id/secret and the given authorization code;
 // note that it is the responsibility of the calling package to verify the provided state parameter, which should be a cryptographically secure random string
 // used to protect against cross-site request forgery attacks. Packages which fail to verify the integrity of the state parameter provided alongside the code
-// parameter passed into this function are vulnerable.
-func AuthorizeBearerToken(code string) (*OAuthResponse, error) {
+// parameter passed into this function are vulnerable. CallbackHandler verifies the state parameter before invoking this function.
+// The options used to build the authorization URL may be passed, in which case the redirect_uri, if any, is sent as required by uphold.
+func (c *Client) AuthorizeBearerToken(ctx context.Context, code string, opts ...AuthorizationURLOption) (*OAuthResponse, error) {
 	var apiResponse *OAuthResponse
 	var err error
 
-	client, err := NewUpholdAPIClient(nil, nil)
+	client, err := c.NewAPIClient(nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := client.PostWWWFormURLEncoded("oauth2/token", map[string]interface{}{
+	params := map[string]interface{}{
 		"code":       code,
 		"grant_type": "authorization_code",
-	}, &apiResponse)
+	}
+	setRedirectURIParam(params, opts)
+
+	status, err := client.PostWWWFormURLEncodedContext(ctx, "oauth2/token", params, &apiResponse)
 	if err != nil {
-		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", upholdClientID, err.Error())
+		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", c.config.ClientID, err.Error())
 		return nil, err
 	}
 
-	log.Debugf("Received %d status code in response to attempted client credentials authorization request on behalf of client id: %s; response: %s", status, upholdClientID, apiResponse)
+	log.Debugf("Received %d status code in response to attempted client credentials authorization request on behalf of client id: %s; response: %s", status, c.config.ClientID, apiResponse)
 
 	if status == 200 {
+		apiResponse.resolveExpiry(time.Now())
 		log.Debugf("Resolved uphold %s access token: %s; refresh token: %s; scope: %s", apiResponse.TokenType, apiResponse.AccessToken, apiResponse.RefreshToken, apiResponse.Scope)
 		// if response, responseOk := resp.(map[string]interface{}); responseOk {
 		// 	apiResponse = &AccessTokenResponse{}
@@ -46,12 +54,12 @@ func AuthorizeBearerToken(code string) (*OAuthResponse, error) {
 		// 	}
 		// 	log.Debugf("Resolved uphold %s access token: %s; refresh token: %s; scope: %s", apiResponse.TokenType, apiResponse.AccessToken, apiResponse.RefreshToken, apiResponse.Scope)
 		// } else {
-		// 	err = fmt.Errorf("Failed to parse client credentials API response on behalf of client id: %s; status code: %d", upholdClientID, status)
+		// 	err = fmt.Errorf("Failed to parse client credentials API response on behalf of client id: %s; status code: %d", c.config.ClientID, status)
 		// 	log.Warning(err.Error())
 		// 	return nil, err
 		// }
 	} else {
-		err = fmt.Errorf("Failed to authorize client credentials on behalf of client id: %s; status code: %d", upholdClientID, status)
+		err = fmt.Errorf("Failed to authorize client credentials on behalf of client id: %s; status code: %d", c.config.ClientID, status)
 		log.Warning(err.Error())
 		return nil, err
 	}
@@ -59,25 +67,117 @@ func AuthorizeBearerToken(code string) (*OAuthResponse, error) {
 	return apiResponse, err
 }
 
-// AuthorizeClientCredentials synchronously authorizes an uphold API user using the environment-configured client id and secret
-func AuthorizeClientCredentials(scope string) (*string, error) {
+// AuthorizeBearerTokenPKCE synchronously authorizes a managed uphold API user using the configured client id, the given
+// authorization code and the PKCE code verifier whose challenge was included in the authorization URL; the client secret
+// is not sent, so public clients which cannot keep it confidential may use this flow. See AuthorizeBearerToken regarding
+// verification of the state parameter and the redirect_uri.
+func (c *Client) AuthorizeBearerTokenPKCE(ctx context.Context, code, codeVerifier string, opts ...AuthorizationURLOption) (*OAuthResponse, error) {
+	var apiResponse *OAuthResponse
+	var err error
+
+	client, err := c.NewUnauthorizedAPIClient(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"client_id":     c.config.ClientID,
+		"code":          code,
+		"code_verifier": codeVerifier,
+		"grant_type":    "authorization_code",
+	}
+	setRedirectURIParam(params, opts)
+
+	status, err := client.PostWWWFormURLEncodedContext(ctx, "oauth2/token", params, &apiResponse)
+	if err != nil {
+		log.Warningf("Failed to authorize bearer token using PKCE on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status != 200 || apiResponse == nil {
+		err = fmt.Errorf("Failed to authorize bearer token using PKCE on behalf of client id: %s; status code: %d", c.config.ClientID, status)
+		log.Warning(err.Error())
+		return nil, err
+	}
+
+	apiResponse.resolveExpiry(time.Now())
+	log.Debugf("Resolved uphold %s access token using PKCE on behalf of client id: %s", apiResponse.TokenType, c.config.ClientID)
+
+	return apiResponse, nil
+}
+
+// AuthorizeClientCredentials synchronously authorizes an uphold API user using the configured client id and secret, requesting
+// the given space-separated scope; the scope is omitted from the request when empty, in which case uphold grants the scope
+// configured for the application
+func (c *Client) AuthorizeClientCredentials(ctx context.Context, scope string) (*OAuthResponse, error) {
 	var apiResponse *OAuthResponse
 	var err error
 
-	client, err := NewUpholdAPIClient(nil, nil)
+	client, err := c.NewAPIClient(nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := client.PostWWWFormURLEncoded("oauth2/token", map[string]interface{}{
+	params := map[string]interface{}{
 		"grant_type": "client_credentials",
-	}, &apiResponse)
+	}
+	if scope != "" {
+		params["scope"] = scope
+	}
+
+	status, err := client.PostWWWFormURLEncodedContext(ctx, "oauth2/token", params, &apiResponse)
 	if err != nil {
-		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", upholdClientID, err.Error())
+		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Received %d status code in response to attempted client credentials authorization request on behalf of client id: %s", status, c.config.ClientID)
+
+	if status != 200 || apiResponse == nil || apiResponse.AccessToken == nil {
+		err = fmt.Errorf("Failed to authorize client credentials on behalf of client id: %s; status code: %d", c.config.ClientID, status)
+		log.Warning(err.Error())
 		return nil, err
 	}
 
-	log.Debugf("Received %d status code in response to attempted client credentials authorization request on behalf of client id: %s; response: %s", status, upholdClientID, apiResponse)
+	apiResponse.resolveExpiry(time.Now())
+	return apiResponse, nil
+}
+
+// AuthorizeBearerToken synchronously authorizes a managed uphold API user using the environment-configured client id/secret and the given authorization code;
+// see Client.AuthorizeBearerToken regarding verification of the state parameter and the redirect_uri.
+func AuthorizeBearerToken(code string, opts ...AuthorizationURLOption) (*OAuthResponse, error) {
+	return AuthorizeBearerTokenContext(context.Background(), code, opts...)
+}
 
-	return apiResponse.AccessToken, err
+// AuthorizeBearerTokenContext synchronously authorizes a managed uphold API user using the environment-configured client id/secret
+// and the given authorization code; the request is bound to the given context. See AuthorizeBearerToken regarding verification
+// of the state parameter and the redirect_uri.
+func AuthorizeBearerTokenContext(ctx context.Context, code string, opts ...AuthorizationURLOption) (*OAuthResponse, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.AuthorizeBearerToken(ctx, code, opts...)
+}
+
+// AuthorizeClientCredentials synchronously authorizes an uphold API user using the environment-configured client id and secret,
+// requesting the given space-separated scope
+func AuthorizeClientCredentials(scope string) (*OAuthResponse, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.AuthorizeClientCredentials(context.Background(), scope)
+}
+
+// setRedirectURIParam sets the redirect_uri token request parameter from the given authorization URL options, if any;
+// the other authorization URL parameters are not part of the token request
+func setRedirectURIParam(params map[string]interface{}, opts []AuthorizationURLOption) {
+	values := url.Values{}
+	for _, opt := range opts {
+		opt(values)
+	}
+	if redirectURI := values.Get("redirect_uri"); redirectURI != "" {
+		params["redirect_uri"] = redirectURI
+	}
 }
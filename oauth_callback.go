@@ -0,0 +1,198 @@
+package uphold
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultStateCookieName = "uphold_oauth_state"
+const defaultStateCookieMaxAge = time.Minute * 10
+
+// ErrStateMismatch is returned when the state parameter of an OAuth callback does not match the persisted state
+var ErrStateMismatch = errors.New("uphold OAuth state parameter mismatch")
+
+// ErrMissingCode is returned when an OAuth callback does not include an authorization code
+var ErrMissingCode = errors.New("uphold OAuth callback is missing the authorization code")
+
+// StateStore persists the OAuth state parameter between the authorization redirect and the callback,
+// typically in the user's session
+type StateStore interface {
+	Save(w http.ResponseWriter, r *http.Request, state string) error
+	Consume(w http.ResponseWriter, r *http.Request) (string, error) // returns and clears the persisted state
+}
+
+// CookieStateStore is a StateStore which persists the state in a short-lived, HTTP-only cookie
+type CookieStateStore struct {
+	Name   string        // the cookie name; defaults to uphold_oauth_state
+	Path   string        // the cookie path; defaults to /
+	MaxAge time.Duration // the cookie lifetime; defaults to 10 minutes
+	Secure bool          // when true, the cookie is only sent over HTTPS
+}
+
+// AuthorizationError is returned when uphold redirects to the callback with an error rather than an authorization code
+type AuthorizationError struct {
+	Code        string // the OAuth error code, e.g., access_denied
+	Description string // the human readable error description, if any
+}
+
+// CallbackHandler is an http.Handler for the OAuth redirect URI which verifies the state parameter against
+// the StateStore, exchanges the authorization code for an access token and passes the result to OnSuccess,
+// which is required
+type CallbackHandler struct {
+	Client    *Client
+	States    StateStore
+	OnSuccess func(w http.ResponseWriter, r *http.Request, resp *OAuthResponse)
+	OnError   func(w http.ResponseWriter, r *http.Request, err error) // defaults to responding with 400 Bad Request and a generic message
+
+	// RedirectURI is the redirect_uri included in the authorization URL using WithRedirectURI, if any;
+	// uphold requires the same value to be sent when the authorization code is exchanged
+	RedirectURI string
+
+	// CodeVerifier, when non-nil, resolves the PKCE code verifier persisted for the request, in which case
+	// the authorization code is exchanged using AuthorizeBearerTokenPKCE rather than the client secret
+	CodeVerifier func(w http.ResponseWriter, r *http.Request) (string, error)
+}
+
+// Error implements the error interface
+func (e *AuthorizationError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("uphold authorization failed; %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("uphold authorization failed; %s", e.Code)
+}
+
+// Save implements StateStore
+func (s *CookieStateStore) Save(w http.ResponseWriter, r *http.Request, state string) error {
+	maxAge := s.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultStateCookieMaxAge
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.name(),
+		Value:    state,
+		Path:     s.path(),
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   s.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Consume implements StateStore
+func (s *CookieStateStore) Consume(w http.ResponseWriter, r *http.Request) (string, error) {
+	cookie, err := r.Cookie(s.name())
+	if err != nil {
+		return "", ErrStateMismatch
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.name(),
+		Value:    "",
+		Path:     s.path(),
+		MaxAge:   -1,
+		Secure:   s.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return cookie.Value, nil
+}
+
+func (s *CookieStateStore) name() string {
+	if s.Name == "" {
+		return defaultStateCookieName
+	}
+	return s.Name
+}
+
+func (s *CookieStateStore) path() string {
+	if s.Path == "" {
+		return "/"
+	}
+	return s.Path
+}
+
+// RedirectToAuthorization generates a new state parameter, persists it using the given StateStore and
+// redirects to the webapp authorization URL for the given scopes
+func (c *Client) RedirectToAuthorization(w http.ResponseWriter, r *http.Request, states StateStore, scopes Scopes, opts ...AuthorizationURLOption) error {
+	authURL, state, err := c.NewAuthorizationURL(scopes, opts...)
+	if err != nil {
+		return err
+	}
+
+	if err := states.Save(w, r, state); err != nil {
+		log.Warningf("Failed to persist OAuth state parameter on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return err
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+	return nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.OnSuccess == nil {
+		log.Warningf("Failed to handle uphold OAuth callback; no OnSuccess handler configured")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := h.authorize(w, r)
+	if err != nil {
+		log.Warningf("Failed to handle uphold OAuth callback; %s", err.Error())
+		if h.OnError != nil {
+			h.OnError(w, r, err)
+		} else {
+			http.Error(w, "uphold authorization failed", http.StatusBadRequest)
+		}
+		return
+	}
+
+	h.OnSuccess(w, r, resp)
+}
+
+func (h *CallbackHandler) authorize(w http.ResponseWriter, r *http.Request) (*OAuthResponse, error) {
+	params := r.URL.Query()
+
+	// consume the persisted state regardless of outcome so it cannot be replayed
+	expected, err := h.States.Consume(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Get("error") != "" {
+		return nil, &AuthorizationError{
+			Code:        params.Get("error"),
+			Description: params.Get("error_description"),
+		}
+	}
+
+	state := params.Get("state")
+	if expected == "" || subtle.ConstantTimeCompare([]byte(state), []byte(expected)) != 1 {
+		return nil, ErrStateMismatch
+	}
+
+	code := params.Get("code")
+	if code == "" {
+		return nil, ErrMissingCode
+	}
+
+	var opts []AuthorizationURLOption
+	if h.RedirectURI != "" {
+		opts = append(opts, WithRedirectURI(h.RedirectURI))
+	}
+
+	if h.CodeVerifier != nil {
+		verifier, err := h.CodeVerifier(w, r)
+		if err != nil {
+			return nil, err
+		}
+		return h.Client.AuthorizeBearerTokenPKCE(r.Context(), code, verifier, opts...)
+	}
+
+	return h.Client.AuthorizeBearerToken(r.Context(), code, opts...)
+}
@@ -0,0 +1,255 @@
+package uphold
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testRedirectURI = "https://app.example.com/uphold/callback"
+
+// tokenEndpoint is a stub uphold oauth2/token endpoint which records the token requests it receives
+type tokenEndpoint struct {
+	mutex    sync.Mutex
+	requests []url.Values
+	basic    []bool
+}
+
+func (e *tokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/oauth2/token" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	r.ParseForm()
+	_, _, basic := r.BasicAuth()
+
+	e.mutex.Lock()
+	e.requests = append(e.requests, r.PostForm)
+	e.basic = append(e.basic, basic)
+	e.mutex.Unlock()
+
+	if r.PostForm.Get("code") != "valid-code" {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"The authorization code is invalid"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"access_token":"access","token_type":"bearer","expires_in":3600,"refresh_token":"refresh","scope":"cards:read"}`))
+}
+
+// callbackResult records the OAuth response dispatched to OnSuccess by a CallbackHandler
+type callbackResult struct {
+	resp *OAuthResponse
+}
+
+func newTestCallbackHandler(t *testing.T) (*CallbackHandler, *tokenEndpoint, *callbackResult) {
+	t.Helper()
+
+	endpoint := &tokenEndpoint{}
+	server := httptest.NewServer(endpoint)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{
+		BaseURL:      "https://sandbox.example.com",
+		APIBaseURL:   server.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	}, WithRetryPolicy(&RetryPolicy{MaxAttempts: 1}))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %s", err)
+	}
+
+	result := &callbackResult{}
+	handler := &CallbackHandler{
+		Client: client,
+		States: &CookieStateStore{},
+		OnSuccess: func(w http.ResponseWriter, r *http.Request, resp *OAuthResponse) {
+			result.resp = resp
+			w.WriteHeader(http.StatusNoContent)
+		},
+		RedirectURI: testRedirectURI,
+	}
+	return handler, endpoint, result
+}
+
+// callbackRequest builds a callback request with the given query and, if non-empty, the given state cookie
+func callbackRequest(query, cookieState string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/uphold/callback?"+query, nil)
+	if cookieState != "" {
+		r.AddCookie(&http.Cookie{Name: defaultStateCookieName, Value: cookieState})
+	}
+	return r
+}
+
+// stateCookieCleared returns true if the response expires the state cookie, so the state cannot be replayed
+func stateCookieCleared(w *httptest.ResponseRecorder) bool {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == defaultStateCookieName && cookie.MaxAge < 0 && cookie.Value == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCallbackHandlerRejectsInvalidCallbacks(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		cookieState string
+		wantErr     error
+	}{
+		{"missing cookie", "state=abc&code=valid-code", "", ErrStateMismatch},
+		{"mismatched state", "state=abc&code=valid-code", "abd", ErrStateMismatch},
+		{"missing state", "code=valid-code", "abc", ErrStateMismatch},
+		{"empty persisted state", "state=&code=valid-code", "", ErrStateMismatch},
+		{"missing code", "state=abc", "abc", ErrMissingCode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler, endpoint, result := newTestCallbackHandler(t)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, callbackRequest(test.query, test.cookieState))
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d; want 400", w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != "uphold authorization failed" {
+				t.Errorf("body = %q; want generic message", body)
+			}
+			if result.resp != nil || len(endpoint.requests) != 0 {
+				t.Errorf("authorization code was exchanged for an invalid callback")
+			}
+			if test.cookieState != "" && !stateCookieCleared(w) {
+				t.Errorf("state cookie was not cleared")
+			}
+
+			var dispatched error
+			handler.OnError = func(w http.ResponseWriter, r *http.Request, err error) {
+				dispatched = err
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), callbackRequest(test.query, test.cookieState))
+			if !errors.Is(dispatched, test.wantErr) {
+				t.Errorf("OnError received %v; want %v", dispatched, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallbackHandlerAuthorizationError(t *testing.T) {
+	handler, endpoint, result := newTestCallbackHandler(t)
+
+	var dispatched error
+	handler.OnError = func(w http.ResponseWriter, r *http.Request, err error) {
+		dispatched = err
+		w.WriteHeader(http.StatusForbidden)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, callbackRequest("state=abc&error=access_denied&error_description=The+user+denied+access", "abc"))
+
+	var authErr *AuthorizationError
+	if !errors.As(dispatched, &authErr) || authErr.Code != "access_denied" || authErr.Description != "The user denied access" {
+		t.Errorf("OnError received %v; want access_denied *AuthorizationError", dispatched)
+	}
+	if w.Code != http.StatusForbidden || result.resp != nil || len(endpoint.requests) != 0 {
+		t.Errorf("unexpected dispatch for error callback")
+	}
+	if !stateCookieCleared(w) {
+		t.Errorf("state cookie was not cleared")
+	}
+}
+
+func TestCallbackHandlerExchangeFailureIsNotLeaked(t *testing.T) {
+	handler, endpoint, result := newTestCallbackHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, callbackRequest("state=abc&code=expired-code", "abc"))
+
+	if w.Code != http.StatusBadRequest || len(endpoint.requests) != 1 || result.resp != nil {
+		t.Errorf("status = %d after %d token request(s); want 400 after 1", w.Code, len(endpoint.requests))
+	}
+	if body := w.Body.String(); strings.Contains(body, "invalid_grant") || strings.Contains(body, "oauth2/token") {
+		t.Errorf("error details were sent to the browser: %q", body)
+	}
+}
+
+func TestCallbackHandlerSuccess(t *testing.T) {
+	handler, endpoint, result := newTestCallbackHandler(t)
+
+	// the state persisted by RedirectToAuthorization must be accepted by the callback
+	redirect := httptest.NewRecorder()
+	err := handler.Client.RedirectToAuthorization(redirect, httptest.NewRequest(http.MethodGet, "/login", nil), handler.States, NewScopes(ScopeCardsRead), WithRedirectURI(testRedirectURI))
+	if err != nil {
+		t.Fatalf("RedirectToAuthorization returned error: %s", err)
+	}
+	location, err := url.Parse(redirect.Header().Get("Location"))
+	if err != nil || redirect.Code != http.StatusFound {
+		t.Fatalf("unexpected redirect: %d %s", redirect.Code, redirect.Header().Get("Location"))
+	}
+	state := location.Query().Get("state")
+	if state == "" || location.Query().Get("redirect_uri") != testRedirectURI {
+		t.Fatalf("authorization URL is missing state or redirect_uri: %s", location)
+	}
+
+	r := callbackRequest(url.Values{"state": {state}, "code": {"valid-code"}}.Encode(), "")
+	for _, cookie := range redirect.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent || result.resp == nil {
+		t.Fatalf("status = %d; want OnSuccess to be invoked", w.Code)
+	}
+	if *result.resp.AccessToken != "access" || result.resp.ExpiresAt == nil {
+		t.Errorf("unexpected OAuth response: %+v", result.resp)
+	}
+	if !stateCookieCleared(w) {
+		t.Errorf("state cookie was not cleared")
+	}
+
+	if len(endpoint.requests) != 1 {
+		t.Fatalf("sent %d token requests; want 1", len(endpoint.requests))
+	}
+	form := endpoint.requests[0]
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "valid-code" || form.Get("redirect_uri") != testRedirectURI || !endpoint.basic[0] {
+		t.Errorf("unexpected token request: %v (basic auth: %v)", form, endpoint.basic[0])
+	}
+}
+
+func TestCallbackHandlerPKCE(t *testing.T) {
+	handler, endpoint, result := newTestCallbackHandler(t)
+	handler.CodeVerifier = func(w http.ResponseWriter, r *http.Request) (string, error) {
+		return "verifier", nil
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, callbackRequest("state=abc&code=valid-code", "abc"))
+
+	if w.Code != http.StatusNoContent || result.resp == nil || len(endpoint.requests) != 1 {
+		t.Fatalf("status = %d; want OnSuccess to be invoked", w.Code)
+	}
+	form := endpoint.requests[0]
+	if form.Get("code_verifier") != "verifier" || form.Get("client_id") != "client-id" || form.Get("redirect_uri") != testRedirectURI || endpoint.basic[0] {
+		t.Errorf("unexpected PKCE token request: %v (basic auth: %v)", form, endpoint.basic[0])
+	}
+}
+
+func TestCallbackHandlerWithoutOnSuccess(t *testing.T) {
+	handler, endpoint, _ := newTestCallbackHandler(t)
+	handler.OnSuccess = nil
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, callbackRequest("state=abc&code=valid-code", "abc"))
+
+	if w.Code != http.StatusInternalServerError || len(endpoint.requests) != 0 {
+		t.Errorf("status = %d after %d token request(s); want 500 without exchanging the code", w.Code, len(endpoint.requests))
+	}
+}
@@ -0,0 +1,99 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const otpTokenHeader = "OTP-Token"
+const otpMethodIDHeader = "OTP-Method-Id"
+
+type otpContextKey struct{}
+
+// OTP is a one-time password used to authorize a sensitive request, e.g., a large withdrawal
+type OTP struct {
+	Token    string // the one-time password entered by the user
+	MethodID string // the id of the OTP method which issued the password, if any
+}
+
+// OTPRequiredError is returned when uphold requires a one-time password to complete the request;
+// the request may be retried with the password using ContextWithOTP or an OTPProvider
+type OTPRequiredError struct {
+	APIError *Error // the underlying API error
+	MethodID string // the id of the OTP method uphold expects the password from, if provided
+}
+
+// Error implements the error interface
+func (e *OTPRequiredError) Error() string {
+	if e.MethodID != "" {
+		return fmt.Sprintf("uphold one-time password required (method %s); %s", e.MethodID, e.APIError.Error())
+	}
+	return fmt.Sprintf("uphold one-time password required; %s", e.APIError.Error())
+}
+
+// Unwrap returns the underlying *Error
+func (e *OTPRequiredError) Unwrap() error {
+	return e.APIError
+}
+
+// OTPProvider is invoked when uphold challenges a request for a one-time password, e.g., to prompt
+// the user; the request is retried once with the returned password
+type OTPProvider func(ctx context.Context, challenge *OTPRequiredError) (*OTP, error)
+
+// WithOTPProvider configures the APIClient to answer one-time password challenges using the given provider
+func WithOTPProvider(provider OTPProvider) APIClientOption {
+	return func(c *APIClient) {
+		c.OTPProvider = provider
+	}
+}
+
+// ContextWithOTP returns a context which sends the given one-time password with requests bound to it;
+// use it to retry a request which failed with an *OTPRequiredError
+func ContextWithOTP(ctx context.Context, otp *OTP) context.Context {
+	return context.WithValue(ctx, otpContextKey{}, otp)
+}
+
+// otpFromContext returns the one-time password bound to ctx, if any
+func otpFromContext(ctx context.Context) *OTP {
+	otp, _ := ctx.Value(otpContextKey{}).(*OTP)
+	return otp
+}
+
+// header returns a copy of the given request headers which includes the one-time password
+func (o *OTP) header(reqHeader http.Header) http.Header {
+	header := reqHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(otpTokenHeader, o.Token)
+	if o.MethodID != "" {
+		header.Set(otpMethodIDHeader, o.MethodID)
+	}
+	return header
+}
+
+// isOTPChallenge returns true if the given API error challenges the request for a one-time password
+func isOTPChallenge(apiErr *Error) bool {
+	return apiErr.Code == errorCodeOTPRequired || strings.EqualFold(apiErr.Header.Get(otpTokenHeader), "required")
+}
+
+// newOTPRequiredError wraps the given API error which challenged the request for a one-time password
+func newOTPRequiredError(apiErr *Error) *OTPRequiredError {
+	return &OTPRequiredError{
+		APIError: apiErr,
+		MethodID: apiErr.Header.Get(otpMethodIDHeader),
+	}
+}
+
+// IsOTPRequired returns true if err was returned because uphold requires a one-time password to complete the request
+func IsOTPRequired(err error) bool {
+	var otpErr *OTPRequiredError
+	if errors.As(err, &otpErr) {
+		return true
+	}
+	apiErr, ok := AsError(err)
+	return ok && isOTPChallenge(apiErr)
+}
@@ -0,0 +1,174 @@
+package uphold
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxPageSize is the maximum number of items uphold returns for a single Range request
+const maxPageSize = 50
+
+// ContentRange describes the Content-Range header returned by uphold list endpoints, e.g., items 0-49/120
+type ContentRange struct {
+	Start int // the index of the first item returned
+	End   int // the index of the last item returned
+	Total int // the total number of items, or -1 if unknown
+}
+
+// Pager walks an uphold list endpoint page by page using Range request headers
+type Pager[T any] struct {
+	client   *APIClient
+	uri      string
+	params   map[string]interface{}
+	pageSize int
+	offset   int
+	total    int
+	done     bool
+
+	// buffered holds the items of the most recently fetched page which an iterator stopped before yielding
+	buffered []T
+}
+
+// NewPager initializes a Pager which fetches pages of at most pageSize items from the given uri;
+// page sizes which are not positive or exceed the uphold maximum of 50 items are clamped
+func NewPager[T any](client *APIClient, uri string, params map[string]interface{}, pageSize int) *Pager[T] {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	return &Pager[T]{
+		client:   client,
+		uri:      uri,
+		params:   params,
+		pageSize: pageSize,
+		total:    -1,
+	}
+}
+
+// HasNext returns true until the final page has been fetched and returned
+func (p *Pager[T]) HasNext() bool {
+	return len(p.buffered) > 0 || !p.done
+}
+
+// Total returns the total number of items reported by uphold, or -1 if it is not yet known
+func (p *Pager[T]) Total() int {
+	return p.total
+}
+
+// Next fetches the next page of items; it returns an empty page once the final page has been fetched. When an
+// iterator returned by Items was stopped early, the items of its page which were not yielded are returned first.
+func (p *Pager[T]) Next(ctx context.Context) ([]T, error) {
+	if len(p.buffered) > 0 {
+		page := p.buffered
+		p.buffered = nil
+		return page, nil
+	}
+
+	if p.done {
+		return nil, nil
+	}
+
+	var page []T
+	status, contentRange, err := p.client.GetRangeContext(ctx, p.uri, p.params, p.offset, p.offset+p.pageSize-1, &page)
+	if err != nil {
+		if status == http.StatusRequestedRangeNotSatisfiable {
+			// the offset is beyond the final item
+			p.done = true
+			return nil, nil
+		}
+		log.Warningf("Failed to fetch page of uphold API (%s) items at offset %d; %s", p.uri, p.offset, err.Error())
+		return nil, err
+	}
+
+	if contentRange == nil {
+		// the endpoint does not paginate; the response contains every item
+		p.done = true
+		return page, nil
+	}
+
+	p.total = contentRange.Total
+	p.offset = contentRange.End + 1
+	if len(page) == 0 || (p.total >= 0 && p.offset >= p.total) || (p.total < 0 && len(page) < p.pageSize) {
+		p.done = true
+	}
+
+	return page, nil
+}
+
+// All fetches every remaining page and returns the concatenated items
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	items := make([]T, 0)
+	for p.HasNext() {
+		page, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+	}
+	return items, nil
+}
+
+// Items returns an iterator which streams every remaining item, fetching pages as needed;
+// iteration stops after yielding the first error encountered. When the caller stops early, the
+// remaining items are returned by subsequent calls to Next, All or Items.
+func (p *Pager[T]) Items(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.HasNext() {
+			page, err := p.Next(ctx)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for i, item := range page {
+				if !yield(item, nil) {
+					p.buffered = page[i+1:]
+					return
+				}
+			}
+		}
+	}
+}
+
+// parseContentRange parses a Content-Range header of the form items 0-49/120, returning nil if it is malformed
+func parseContentRange(val string) *ContentRange {
+	unit, spec, ok := strings.Cut(strings.TrimSpace(val), " ")
+	if !ok || unit != "items" {
+		return nil
+	}
+
+	span, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return nil
+	}
+
+	startStr, endStr, ok := strings.Cut(span, "-")
+	if !ok {
+		return nil
+	}
+
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return nil
+	}
+	end, err := strconv.Atoi(endStr)
+	if err != nil {
+		return nil
+	}
+
+	contentRange := &ContentRange{
+		Start: start,
+		End:   end,
+		Total: -1,
+	}
+	if total != "*" {
+		if contentRange.Total, err = strconv.Atoi(total); err != nil {
+			return nil
+		}
+	}
+
+	return contentRange
+}
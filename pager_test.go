@@ -0,0 +1,162 @@
+package uphold
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newRangeServer serves the given items using Range request headers as uphold list endpoints do; the total
+// is reported in Content-Range unless hideTotal is set, and ranges beyond the final item are rejected with 416
+func newRangeServer(t *testing.T, items []int, hideTotal bool) (*APIClient, *int) {
+	t.Helper()
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "items=%d-%d", &start, &end); err != nil {
+			t.Errorf("malformed Range header: %q", r.Header.Get("Range"))
+		}
+		if start >= len(items) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		end = min(end, len(items)-1)
+
+		total := fmt.Sprint(len(items))
+		if hideTotal {
+			total = "*"
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("items %d-%d/%s", start, end, total))
+		json.NewEncoder(w).Encode(items[start : end+1])
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, _ := url.Parse(server.URL)
+	return &APIClient{
+		Host:        serverURL.Host,
+		Scheme:      serverURL.Scheme,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 1},
+	}, &requests
+}
+
+func sequence(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+func TestPagerAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int
+		pageSize  int
+		hideTotal bool
+		requests  int
+	}{
+		{"exact pages", 8, 4, false, 2},
+		{"partial final page", 10, 4, false, 3},
+		{"empty", 0, 4, false, 1},
+		{"unknown total with partial final page", 10, 4, true, 3},
+		{"unknown total ending at page boundary", 8, 4, true, 3}, // the final request is answered with 416
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, requests := newRangeServer(t, sequence(test.items), test.hideTotal)
+			pager := NewPager[int](client, "items", nil, test.pageSize)
+
+			items, err := pager.All(context.Background())
+			if err != nil {
+				t.Fatalf("All returned error: %s", err)
+			}
+			if !reflect.DeepEqual(items, sequence(test.items)) {
+				t.Errorf("All = %v; want %v", items, sequence(test.items))
+			}
+			if *requests != test.requests {
+				t.Errorf("sent %d requests; want %d", *requests, test.requests)
+			}
+			if pager.HasNext() {
+				t.Errorf("HasNext = true after All")
+			}
+		})
+	}
+}
+
+func TestPagerItemsStoppedEarly(t *testing.T) {
+	client, _ := newRangeServer(t, sequence(10), false)
+	pager := NewPager[int](client, "items", nil, 4)
+
+	for item, err := range pager.Items(context.Background()) {
+		if err != nil {
+			t.Fatalf("Items returned error: %s", err)
+		}
+		if item != 0 {
+			t.Fatalf("first item = %d; want 0", item)
+		}
+		break
+	}
+
+	rest, err := pager.All(context.Background())
+	if err != nil {
+		t.Fatalf("All returned error: %s", err)
+	}
+	if !reflect.DeepEqual(rest, sequence(10)[1:]) {
+		t.Errorf("All after stopping early = %v; want %v", rest, sequence(10)[1:])
+	}
+	if pager.Total() != 10 {
+		t.Errorf("Total = %d; want 10", pager.Total())
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *ContentRange
+	}{
+		{"items 0-49/120", &ContentRange{Start: 0, End: 49, Total: 120}},
+		{" items 50-99/* ", &ContentRange{Start: 50, End: 99, Total: -1}},
+		{"bytes 0-49/120", nil},
+		{"items 0-49", nil},
+		{"items 0/120", nil},
+		{"items a-49/120", nil},
+		{"items 0-49/many", nil},
+		{"", nil},
+	}
+
+	for _, test := range tests {
+		if got := parseContentRange(test.in); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseContentRange(%q) = %+v; want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":"unauthorized","message":"Invalid token"}`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	client := &APIClient{Host: serverURL.Host, Scheme: serverURL.Scheme, RetryPolicy: &RetryPolicy{MaxAttempts: 1}}
+	pager := NewPager[int](client, "items", nil, 4)
+
+	_, err := pager.Next(context.Background())
+	if apiErr, ok := AsError(err); !ok || apiErr.StatusCode != http.StatusUnauthorized || !strings.Contains(apiErr.Message, "Invalid token") {
+		t.Errorf("Next returned %v; want 401 *Error", err)
+	}
+	if !pager.HasNext() {
+		t.Errorf("HasNext = false after a failed request")
+	}
+}
@@ -0,0 +1,192 @@
+package uphold
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// quoteExpirySkew is subtracted from the quote expiry to account for latency and clock skew when committing
+const quoteExpirySkew = time.Second * 2
+
+// slippagePrecision is the number of decimal places to which slippage between quoted rates is computed
+const slippagePrecision = 12
+
+// ErrQuoteExpired is returned when committing a quote which has expired
+var ErrQuoteExpired = errors.New("uphold transaction quote has expired")
+
+// Quote is a transaction which has been quoted but not yet committed; uphold honors the quoted
+// denomination, rate and fees until the quote expires
+type Quote struct {
+	Transaction  *Transaction  // the quoted transaction
+	Denomination *Denomination // the quoted denomination, including the pair and rate
+	Fees         []*Fee        // the fees which will be applied when the quote is committed
+	Rate         *Amount       // the quoted rate, if any
+	ExpiresAt    time.Time     // the time at which the quote expires; zero if uphold did not provide an expiry
+
+	client  *Client
+	token   string
+	cardID  string
+	request *TransactionRequest
+}
+
+// SlippageError is returned when an expired quote is re-quoted at a rate which differs from the
+// originally quoted rate by more than the caller's maximum slippage
+type SlippageError struct {
+	QuotedRate   Amount  // the originally quoted rate
+	RequotedRate Amount  // the rate of the new quote
+	Slippage     float64 // the relative difference between the rates, e.g., 0.01 for 1%
+	MaxSlippage  float64 // the maximum slippage permitted by the caller
+	Requote      *Quote  // the new quote, which may be committed if the caller accepts the slippage
+}
+
+// Error implements the error interface
+func (e *SlippageError) Error() string {
+	return fmt.Sprintf("uphold transaction re-quoted at rate %s; slippage of %.4f%% from quoted rate %s exceeds maximum of %.4f%%", e.RequotedRate, e.Slippage*100, e.QuotedRate, e.MaxSlippage*100)
+}
+
+// QuoteCommitOption configures how a Quote is committed
+type QuoteCommitOption func(*quoteCommitOptions)
+
+type quoteCommitOptions struct {
+	requote     bool
+	maxSlippage float64
+}
+
+// WithRequote configures Quote.Commit to re-quote an expired quote and commit the new quote, provided
+// its rate differs from the originally quoted rate by no more than maxSlippage, e.g., 0.005 for 0.5%
+func WithRequote(maxSlippage float64) QuoteCommitOption {
+	return func(opts *quoteCommitOptions) {
+		opts.requote = true
+		opts.maxSlippage = maxSlippage
+	}
+}
+
+// QuoteTransaction validates and quotes the given transaction request from the card with the given id;
+// the request is never committed in a single step, regardless of its Commit property
+func (c *Client) QuoteTransaction(ctx context.Context, token, cardID string, req *TransactionRequest) (*Quote, error) {
+	quoteReq := *req
+	quoteReq.Commit = false
+
+	tx, err := c.SubmitTransaction(ctx, token, cardID, &quoteReq)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("Failed to quote transaction on behalf of client id: %s; empty response", c.config.ClientID)
+	}
+
+	quote := newQuote(tx)
+	quote.client = c
+	quote.token = token
+	quote.cardID = cardID
+	quote.request = &quoteReq
+
+	log.Debugf("Quoted transaction on behalf of client id: %s; expires at: %s", c.config.ClientID, quote.ExpiresAt)
+	return quote, nil
+}
+
+// newQuote builds a Quote from the given quoted transaction
+func newQuote(tx *Transaction) *Quote {
+	quote := &Quote{
+		Transaction:  tx,
+		Denomination: tx.Denomination,
+		Fees:         tx.Fees,
+	}
+
+	if tx.Denomination != nil && tx.Denomination.Rate != nil {
+		if rate, err := ParseAmount(*tx.Denomination.Rate); err == nil {
+			quote.Rate = &rate
+		}
+	}
+
+	if tx.Params != nil {
+		var params struct {
+			ExpiresAt *time.Time  `json:"expiresAt"`
+			TTL       json.Number `json:"ttl"`
+		}
+		if err := json.Unmarshal(*tx.Params, &params); err != nil {
+			log.Debugf("Failed to parse quoted transaction params; %s", err.Error())
+		} else if params.ExpiresAt != nil {
+			quote.ExpiresAt = *params.ExpiresAt
+		} else if ttl, err := params.TTL.Int64(); err == nil && tx.CreatedAt != nil {
+			quote.ExpiresAt = tx.CreatedAt.Add(time.Duration(ttl) * time.Millisecond)
+		}
+	}
+
+	return quote
+}
+
+// Expired returns true if the quote has expired, or will expire before it could reasonably be committed
+func (q *Quote) Expired() bool {
+	if q.ExpiresAt.IsZero() {
+		return false
+	}
+	return !time.Now().Add(quoteExpirySkew).Before(q.ExpiresAt)
+}
+
+// TTL returns the time remaining until the quote expires, or 0 if it has expired or its expiry is unknown
+func (q *Quote) TTL() time.Duration {
+	if q.ExpiresAt.IsZero() {
+		return 0
+	}
+	if ttl := time.Until(q.ExpiresAt); ttl > 0 {
+		return ttl
+	}
+	return 0
+}
+
+// Commit commits the quoted transaction; ErrQuoteExpired is returned if the quote has expired, unless
+// the caller opted into re-quoting using WithRequote, in which case a *SlippageError is returned if
+// the rate of the new quote exceeds the permitted slippage
+func (q *Quote) Commit(ctx context.Context, opts ...QuoteCommitOption) (*Transaction, error) {
+	options := &quoteCommitOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if q.Transaction == nil || q.Transaction.ID == nil {
+		return nil, fmt.Errorf("Failed to commit uphold transaction quote; quote has no transaction id")
+	}
+
+	if !q.Expired() {
+		return q.client.CommitTransaction(ctx, q.token, q.cardID, q.Transaction.ID.String())
+	}
+
+	if !options.requote {
+		log.Warningf("Refusing to commit uphold transaction quote (tx id: %s) which expired at %s", q.Transaction.ID.String(), q.ExpiresAt)
+		return nil, ErrQuoteExpired
+	}
+
+	requote, err := q.client.QuoteTransaction(ctx, q.token, q.cardID, q.request)
+	if err != nil {
+		return nil, err
+	}
+	if requote.Transaction == nil || requote.Transaction.ID == nil {
+		return nil, fmt.Errorf("Failed to re-quote expired uphold transaction quote (tx id: %s); re-quote has no transaction id", q.Transaction.ID.String())
+	}
+
+	if q.Rate != nil && requote.Rate != nil {
+		slippage := 0.0
+		if !q.Rate.IsZero() {
+			slippage = requote.Rate.Sub(*q.Rate).Abs().Quo(q.Rate.Abs(), slippagePrecision).Float64()
+		}
+		if slippage > options.maxSlippage {
+			err := &SlippageError{
+				QuotedRate:   *q.Rate,
+				RequotedRate: *requote.Rate,
+				Slippage:     slippage,
+				MaxSlippage:  options.maxSlippage,
+				Requote:      requote,
+			}
+			log.Warningf("Refusing to commit re-quoted uphold transaction (tx id: %s); %s", requote.Transaction.ID.String(), err.Error())
+			return nil, err
+		}
+	} else if q.Rate != nil || requote.Rate != nil {
+		return nil, fmt.Errorf("Failed to compare rate of re-quoted uphold transaction with quoted rate; rate unavailable")
+	}
+
+	return requote.Commit(ctx)
+}
@@ -0,0 +1,208 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit describes the request quota most recently reported by uphold via the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset response headers
+type RateLimit struct {
+	Limit     int       // the maximum number of requests permitted in the current window
+	Remaining int       // the number of requests remaining in the current window
+	Reset     time.Time // the time at which the current window resets
+	UpdatedAt time.Time // the time at which the quota was reported
+}
+
+// RateLimitError is returned when uphold rejects a request with a 429 status code
+type RateLimitError struct {
+	APIError   *Error        // the underlying API error
+	RateLimit  *RateLimit    // the quota reported alongside the rejected request, if any
+	RetryAfter time.Duration // the delay requested by uphold before the next request, if any
+}
+
+// RateLimiter tracks the request quota reported by uphold and, when configured with a rate,
+// throttles requests client-side using a token bucket so the quota is not exhausted; a single
+// RateLimiter is shared by all APIClient instances constructed by a Client
+type RateLimiter struct {
+	mutex sync.Mutex
+	quota *RateLimit
+
+	rate   float64 // tokens added per second; 0 disables client-side throttling
+	burst  float64 // the capacity of the bucket
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter initializes a RateLimiter which tracks the uphold quota without throttling requests
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// NewThrottlingRateLimiter initializes a RateLimiter which permits at most requestsPerSecond requests
+// on average, with bursts of up to burst requests, and which blocks until the reported quota resets
+// once uphold reports it has been exhausted
+func NewThrottlingRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+}
+
+// WithRateLimiter configures the APIClient to track the uphold quota, and optionally throttle requests, using the given RateLimiter
+func WithRateLimiter(limiter *RateLimiter) APIClientOption {
+	return func(c *APIClient) {
+		c.RateLimiter = limiter
+	}
+}
+
+// RateLimit returns a copy of the most recently reported quota, or nil if no quota has been reported
+func (l *RateLimiter) RateLimit() *RateLimit {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.quota == nil {
+		return nil
+	}
+	quota := *l.quota
+	return &quota
+}
+
+// Wait blocks until a request may be sent without exceeding the client-side rate or the reported quota;
+// it returns immediately when the RateLimiter does not throttle requests
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+
+		log.Debugf("Throttling uphold API invocation for %v", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token from the bucket and returns 0, or returns the delay until one is available
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	if l.quota != nil && l.quota.Remaining <= 0 && now.Before(l.quota.Reset) {
+		return l.quota.Reset.Sub(now)
+	}
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		if l.quota != nil && l.quota.Remaining > 0 {
+			l.quota.Remaining--
+		}
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// update records the quota reported in the given response headers, if any
+func (l *RateLimiter) update(header http.Header) {
+	quota := parseRateLimit(header)
+	if quota == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.quota = quota
+}
+
+// parseRateLimit parses the uphold rate limit headers, returning nil if they are absent
+func parseRateLimit(header http.Header) *RateLimit {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil
+	}
+
+	quota := &RateLimit{
+		Limit:     limit,
+		UpdatedAt: time.Now(),
+	}
+
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		quota.Remaining = remaining
+	}
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		quota.Reset = time.Unix(reset, 0)
+	}
+
+	return quota
+}
+
+// newRateLimitError wraps the given *Error, which must describe a 429 response
+func newRateLimitError(apiErr *Error) *RateLimitError {
+	rateLimitErr := &RateLimitError{
+		APIError:  apiErr,
+		RateLimit: parseRateLimit(apiErr.Header),
+	}
+
+	if retryAfter, retryAfterOk := parseRetryAfter(apiErr.Header.Get("Retry-After")); retryAfterOk {
+		rateLimitErr.RetryAfter = retryAfter
+	} else if rateLimitErr.RateLimit != nil && !rateLimitErr.RateLimit.Reset.IsZero() {
+		rateLimitErr.RetryAfter = time.Until(rateLimitErr.RateLimit.Reset)
+	}
+
+	return rateLimitErr
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	if e.RateLimit != nil && !e.RateLimit.Reset.IsZero() {
+		return fmt.Sprintf("%s; rate limit of %d requests exceeded until %s", e.APIError.Error(), e.RateLimit.Limit, e.RateLimit.Reset.Format(time.RFC3339))
+	}
+	return e.APIError.Error()
+}
+
+// Unwrap returns the underlying *Error
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// Reset returns the time at which requests may resume
+func (e *RateLimitError) Reset() time.Time {
+	if e.RateLimit != nil && !e.RateLimit.Reset.IsZero() {
+		return e.RateLimit.Reset
+	}
+	return time.Now().Add(e.RetryAfter)
+}
+
+// IsRateLimited returns true if err was returned because the uphold rate limit was exceeded
+func IsRateLimited(err error) bool {
+	var rateLimitErr *RateLimitError
+	return errors.As(err, &rateLimitErr)
+}
@@ -0,0 +1,171 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const defaultRetryMaxAttempts = 3
+const defaultRetryMinBackoff = time.Millisecond * 250
+const defaultRetryMaxBackoff = time.Second * 10
+
+type retryContextKey struct{}
+
+var defaultRetryPolicy = DefaultRetryPolicy()
+
+// retryableStatusCodes are the HTTP status codes considered transient
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// RetryPolicy determines how requests which fail with a transient error are retried; GET and DELETE
+// requests are retried by default, whereas POST and PUT requests are only retried when the policy
+// allows non-idempotent retries or the request context was returned by ContextWithRetries; PATCH
+// requests are treated as POST requests
+type RetryPolicy struct {
+	MaxAttempts        int                 // the maximum number of attempts, including the first; values less than 2 disable retries
+	MinBackoff         time.Duration       // the backoff before the first retry; doubled after each subsequent attempt
+	MaxBackoff         time.Duration       // the maximum backoff between attempts
+	RetryNonIdempotent bool                // when true, POST, PUT and PATCH requests are retried
+	OnRetry            func(*RetryAttempt) // invoked before each retry, if non-nil
+}
+
+// RetryAttempt describes a failed attempt which is about to be retried
+type RetryAttempt struct {
+	Method     string        // the request method
+	URL        string        // the request url
+	Attempt    int           // the number of the failed attempt, starting at 1
+	StatusCode int           // the status code of the failed attempt, or 0 if no response was received
+	Err        error         // the transport error of the failed attempt, if any
+	Backoff    time.Duration // the delay before the next attempt
+}
+
+// DefaultRetryPolicy returns the retry policy used by APIClient instances which have not been configured with their own
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		MinBackoff:  defaultRetryMinBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+	}
+}
+
+// WithRetryPolicy configures the APIClient to retry failed requests using the given policy
+func WithRetryPolicy(policy *RetryPolicy) APIClientOption {
+	return func(c *APIClient) {
+		c.RetryPolicy = policy
+	}
+}
+
+// ContextWithRetries returns a context which opts requests bound to it into retries regardless of method;
+// callers should only use it for requests which are safe to repeat
+func ContextWithRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// allows returns true if a request with the given method and context may be retried
+func (p *RetryPolicy) allows(ctx context.Context, method string) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	}
+
+	if optIn, optInOk := ctx.Value(retryContextKey{}).(bool); optInOk && optIn {
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// backoff returns the delay before the attempt following the given failed attempt; a Retry-After
+// header on the failed response takes precedence over the exponential backoff
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if retryAfter, retryAfterOk := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfterOk {
+			if p.MaxBackoff > 0 && retryAfter > p.MaxBackoff {
+				return p.MaxBackoff
+			}
+			return retryAfter
+		}
+	}
+
+	backoff := p.MinBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			backoff = p.MaxBackoff
+			break
+		}
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	// apply jitter in the range [backoff/2, backoff]
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// shouldRetry returns true if the given response or transport error is considered transient
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTransientError(err)
+	}
+	return resp != nil && retryableStatusCodes[resp.StatusCode]
+}
+
+// isTransientError returns true if the given transport error is likely to succeed when retried, e.g., a timeout,
+// a reset or refused connection or a connection closed before the response was received; errors such as TLS
+// certificate failures or malformed URLs recur on every attempt and are not retried
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header value expressed in either seconds or as an HTTP date
+func parseRetryAfter(val string) (time.Duration, bool) {
+	if val == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(val); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(val); err == nil {
+		delay := time.Until(at)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
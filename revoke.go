@@ -0,0 +1,92 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RevokeToken synchronously revokes the given access or refresh token using the configured client id and secret; a revoked
+// application token is evicted from the Client's cache
+func (c *Client) RevokeToken(ctx context.Context, token string) error {
+	var resp map[string]interface{}
+	var err error
+
+	c.applicationTokens.evict(token)
+
+	client, err := c.NewAPIClient(nil, nil)
+	if err != nil {
+		return err
+	}
+
+	status, err := client.PostWWWFormURLEncodedContext(ctx, "oauth2/revoke", map[string]interface{}{
+		"token": token,
+	}, &resp)
+	if err != nil {
+		log.Warningf("Failed to revoke token on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return err
+	}
+
+	if status >= 200 && status < 300 {
+		log.Debugf("Revoked token on behalf of client id: %s", c.config.ClientID)
+		return nil
+	}
+
+	return fmt.Errorf("Failed to revoke token on behalf of client id: %s; status code: %d", c.config.ClientID, status)
+}
+
+// RevokeUserToken revokes the refresh and access tokens stored for the given user and evicts them from the TokenStore,
+// e.g., when the user disconnects their uphold account; the tokens are evicted even if revocation fails, in which case
+// the revocation error is returned
+func (c *Client) RevokeUserToken(ctx context.Context, userID string) error {
+	if c.tokenStore == nil {
+		return fmt.Errorf("Failed to revoke uphold token for user: %s; no token store configured", userID)
+	}
+
+	lock := c.userLock(userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// a refreshed token which could not be persisted supersedes the stored token
+	var token *Token
+	if unpersisted, unpersistedOk := c.unpersistedTokens.LoadAndDelete(userID); unpersistedOk {
+		token = unpersisted.(*Token)
+	} else {
+		stored, err := c.tokenStore.Get(ctx, userID)
+		if err != nil {
+			if errors.Is(err, ErrTokenNotFound) {
+				return nil
+			}
+			log.Warningf("Failed to load uphold token for user: %s; %s", userID, err.Error())
+			return err
+		}
+		token = stored
+	}
+
+	var revokeErr error
+	if token.RefreshToken != "" {
+		revokeErr = c.RevokeToken(ctx, token.RefreshToken)
+	}
+	if token.AccessToken != "" {
+		if err := c.RevokeToken(ctx, token.AccessToken); err != nil && revokeErr == nil {
+			revokeErr = err
+		}
+	}
+
+	if err := c.tokenStore.Delete(ctx, userID); err != nil {
+		log.Warningf("Failed to evict revoked uphold token for user: %s; %s", userID, err.Error())
+		return err
+	}
+
+	log.Debugf("Evicted uphold token for user: %s", userID)
+	return revokeErr
+}
+
+// RevokeToken synchronously revokes the given access or refresh token using the environment-configured client
+func RevokeToken(token string) error {
+	client, err := DefaultClient()
+	if err != nil {
+		return err
+	}
+	return client.RevokeToken(context.Background(), token)
+}
@@ -0,0 +1,149 @@
+package uphold
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scope is an uphold OAuth scope
+type Scope string
+
+// Supported uphold OAuth scopes
+const (
+	ScopeAccountsRead                    Scope = "accounts:read"
+	ScopeCardsRead                       Scope = "cards:read"
+	ScopeCardsWrite                      Scope = "cards:write"
+	ScopeContactsRead                    Scope = "contacts:read"
+	ScopeContactsWrite                   Scope = "contacts:write"
+	ScopePhonesRead                      Scope = "phones:read"
+	ScopePhonesWrite                     Scope = "phones:write"
+	ScopeTransactionsDeposit             Scope = "transactions:deposit"
+	ScopeTransactionsRead                Scope = "transactions:read"
+	ScopeTransactionsTransferApplication Scope = "transactions:transfer:application"
+	ScopeTransactionsTransferOthers      Scope = "transactions:transfer:others"
+	ScopeTransactionsTransferSelf        Scope = "transactions:transfer:self"
+	ScopeTransactionsWithdraw            Scope = "transactions:withdraw"
+	ScopeUserRead                        Scope = "user:read"
+)
+
+// AllScopes contains every scope supported by this package
+var AllScopes = NewScopes(
+	ScopeAccountsRead,
+	ScopeCardsRead,
+	ScopeCardsWrite,
+	ScopeContactsRead,
+	ScopeContactsWrite,
+	ScopePhonesRead,
+	ScopePhonesWrite,
+	ScopeTransactionsDeposit,
+	ScopeTransactionsRead,
+	ScopeTransactionsTransferApplication,
+	ScopeTransactionsTransferOthers,
+	ScopeTransactionsTransferSelf,
+	ScopeTransactionsWithdraw,
+	ScopeUserRead,
+)
+
+// Scopes is a set of uphold OAuth scopes, kept sorted and free of duplicates
+type Scopes []Scope
+
+// ScopeError is returned before a request is sent when the token it would be sent with lacks a required scope;
+// the check is only made when the scopes granted to the token are known, which requires the token to be provided by a
+// TokenSource, including one bound using Client.WithToken, and its Scope to be set. Requests sent with a raw
+// access token string are not checked.
+type ScopeError struct {
+	Required Scopes // the scopes required by the request
+	Missing  Scopes // the required scopes which were not granted
+	Granted  Scopes // the scopes granted to the token
+	Any      bool   // true if any one of the required scopes would have sufficed
+}
+
+// NewScopes returns the set of the given scopes
+func NewScopes(scopes ...Scope) Scopes {
+	set := make(Scopes, 0, len(scopes))
+	for _, scope := range scopes {
+		if scope != "" {
+			set = append(set, scope)
+		}
+	}
+	slices.Sort(set)
+	return slices.Compact(set)
+}
+
+// ParseScopes parses a space-separated scope string, as granted in an OAuthResponse
+func ParseScopes(str string) Scopes {
+	fields := strings.Fields(str)
+	scopes := make([]Scope, 0, len(fields))
+	for _, field := range fields {
+		scopes = append(scopes, Scope(field))
+	}
+	return NewScopes(scopes...)
+}
+
+// String formats the scopes as a space-separated scope string
+func (s Scopes) String() string {
+	strs := make([]string, 0, len(s))
+	for _, scope := range s {
+		strs = append(strs, string(scope))
+	}
+	return strings.Join(strs, " ")
+}
+
+// Contains returns true if the set contains the given scope
+func (s Scopes) Contains(scope Scope) bool {
+	_, found := slices.BinarySearch(s, scope)
+	return found
+}
+
+// ContainsAll returns true if the set contains every given scope
+func (s Scopes) ContainsAll(scopes ...Scope) bool {
+	return len(s.Missing(scopes...)) == 0
+}
+
+// ContainsAny returns true if the set contains at least one of the given scopes
+func (s Scopes) ContainsAny(scopes ...Scope) bool {
+	return slices.ContainsFunc(scopes, s.Contains)
+}
+
+// Missing returns the given scopes which the set does not contain
+func (s Scopes) Missing(scopes ...Scope) Scopes {
+	missing := make([]Scope, 0)
+	for _, scope := range scopes {
+		if !s.Contains(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return NewScopes(missing...)
+}
+
+// Union returns the set of scopes contained in either set
+func (s Scopes) Union(other Scopes) Scopes {
+	return NewScopes(append(slices.Clone(s), other...)...)
+}
+
+// Validate returns an error if the set contains a scope which is not supported by this package
+func (s Scopes) Validate() error {
+	if unsupported := AllScopes.Missing(s...); len(unsupported) > 0 {
+		return fmt.Errorf("unsupported uphold scope(s): %s", unsupported.String())
+	}
+	if len(s) == 0 {
+		return errors.New("no uphold scopes requested")
+	}
+	return nil
+}
+
+// Error implements the error interface
+func (e *ScopeError) Error() string {
+	if e.Any {
+		return fmt.Sprintf("uphold token lacks any of the required scope(s): %s; granted scope(s): %s", e.Required.String(), e.Granted.String())
+	}
+	return fmt.Sprintf("uphold token lacks required scope(s): %s; granted scope(s): %s", e.Missing.String(), e.Granted.String())
+}
+
+// IsScopeMissing returns true if err was returned because the token lacks a scope required by the request
+func IsScopeMissing(err error) bool {
+	var scopeErr *ScopeError
+	return errors.As(err, &scopeErr)
+}
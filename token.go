@@ -0,0 +1,183 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// tokenExpiryDelta is how long before its expiry an access token is considered expired, so it is
+// refreshed before requests made with it begin to fail
+const tokenExpiryDelta = time.Second * 30
+
+// ErrNoRefreshToken is returned when an expired access token cannot be refreshed because no refresh token is available
+var ErrNoRefreshToken = errors.New("uphold access token has expired and no refresh token is available")
+
+// Token is an uphold OAuth access token along with the information required to refresh it
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"` // the time at which the access token expires; zero if it does not expire
+}
+
+// TokenSource supplies uphold access tokens; as with golang.org/x/oauth2.TokenSource, implementations
+// must be safe for concurrent use and should return a valid, refreshed token from each call to Token
+type TokenSource interface {
+	Token() (*Token, error)
+}
+
+// refreshingTokenSource returns its current token until it expires, then refreshes it using the refresh token
+type refreshingTokenSource struct {
+	ctx    context.Context
+	client *Client
+
+	mutex sync.Mutex
+	token *Token
+}
+
+// resolveExpiry sets ExpiresAt relative to the given time at which the response was received
+func (r *OAuthResponse) resolveExpiry(receivedAt time.Time) {
+	if r.ExpiresIn == nil {
+		return
+	}
+	if seconds, err := r.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		expiresAt := receivedAt.Add(time.Duration(seconds) * time.Second)
+		r.ExpiresAt = &expiresAt
+	}
+}
+
+// Token returns the access token described by the response
+func (r *OAuthResponse) Token() *Token {
+	token := &Token{}
+	if r.AccessToken != nil {
+		token.AccessToken = *r.AccessToken
+	}
+	if r.RefreshToken != nil {
+		token.RefreshToken = *r.RefreshToken
+	}
+	if r.TokenType != nil {
+		token.TokenType = *r.TokenType
+	}
+	if r.Scope != nil {
+		token.Scope = *r.Scope
+	}
+	if r.ExpiresAt != nil {
+		token.Expiry = *r.ExpiresAt
+	}
+	return token
+}
+
+// Valid returns true if the token is non-empty and will not expire imminently
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// RefreshBearerToken synchronously exchanges the given refresh token for a new access token using the configured client id and secret
+func (c *Client) RefreshBearerToken(ctx context.Context, refreshToken string) (*OAuthResponse, error) {
+	var apiResponse *OAuthResponse
+	var err error
+
+	client, err := c.NewAPIClient(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.PostWWWFormURLEncodedContext(ctx, "oauth2/token", map[string]interface{}{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}, &apiResponse)
+	if err != nil {
+		log.Warningf("Failed to refresh bearer token on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status != 200 || apiResponse == nil {
+		err = fmt.Errorf("Failed to refresh bearer token on behalf of client id: %s; status code: %d", c.config.ClientID, status)
+		log.Warning(err.Error())
+		return nil, err
+	}
+
+	apiResponse.resolveExpiry(time.Now())
+	log.Debugf("Refreshed uphold %s access token on behalf of client id: %s; expires at: %s", apiResponse.TokenType, c.config.ClientID, apiResponse.ExpiresAt)
+
+	return apiResponse, nil
+}
+
+// TokenSource returns a TokenSource which returns the given token until it expires, then transparently
+// refreshes it using its refresh token; refresh requests are bound to the given context
+func (c *Client) TokenSource(ctx context.Context, token *Token) TokenSource {
+	return &refreshingTokenSource{
+		ctx:    ctx,
+		client: c,
+		token:  token,
+	}
+}
+
+// Token implements TokenSource
+func (s *refreshingTokenSource) Token() (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.Valid() {
+		return s.token, nil
+	}
+
+	token, err := s.client.refreshToken(s.ctx, s.token)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// refreshToken exchanges the refresh token of the given token for a new token; uphold may omit the refresh
+// token from the response, in which case the existing refresh token is retained
+func (c *Client) refreshToken(ctx context.Context, token *Token) (*Token, error) {
+	if token == nil || token.RefreshToken == "" {
+		return nil, ErrNoRefreshToken
+	}
+
+	resp, err := c.RefreshBearerToken(ctx, token.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := resp.Token()
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	if refreshed.Scope == "" {
+		refreshed.Scope = token.Scope
+	}
+	return refreshed, nil
+}
+
+// StaticTokenSource returns a TokenSource which always returns the given token, without refreshing it
+func StaticTokenSource(token *Token) TokenSource {
+	return staticTokenSource{token}
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+// Token implements TokenSource
+func (s staticTokenSource) Token() (*Token, error) {
+	return s.token, nil
+}
+
+// RefreshBearerToken synchronously exchanges the given refresh token for a new access token using the environment-configured client
+func RefreshBearerToken(refreshToken string) (*OAuthResponse, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.RefreshBearerToken(context.Background(), refreshToken)
+}
@@ -0,0 +1,274 @@
+package uphold
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const fileTokenStoreExtension = ".token"
+
+// ErrTokenNotFound is returned by a TokenStore when no token is stored for the given user
+var ErrTokenNotFound = errors.New("uphold token not found")
+
+// TokenStore persists uphold tokens keyed by the caller's own user id; implementations must be safe for concurrent use
+type TokenStore interface {
+	Get(ctx context.Context, userID string) (*Token, error) // returns ErrTokenNotFound if no token is stored for the user
+	Put(ctx context.Context, userID string, token *Token) error
+	Delete(ctx context.Context, userID string) error // succeeds if no token is stored for the user
+}
+
+// MemoryTokenStore is a TokenStore which holds tokens in memory
+type MemoryTokenStore struct {
+	mutex  sync.RWMutex
+	tokens map[string]*Token
+}
+
+// FileTokenStore is a TokenStore which persists each user's token to its own file in a directory,
+// encrypted and authenticated using AES-GCM; file names are derived from a hash of the user id
+type FileTokenStore struct {
+	dir   string
+	aead  cipher.AEAD
+	mutex sync.Mutex
+}
+
+// storeTokenSource resolves the token of a single user from a TokenStore, refreshing and persisting it as necessary
+type storeTokenSource struct {
+	ctx    context.Context
+	client *Client
+	userID string
+}
+
+// NewMemoryTokenStore initializes an empty MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: map[string]*Token{},
+	}
+}
+
+// Get implements TokenStore
+func (s *MemoryTokenStore) Get(ctx context.Context, userID string) (*Token, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	token, tokenOk := s.tokens[userID]
+	if !tokenOk {
+		return nil, ErrTokenNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+// Put implements TokenStore
+func (s *MemoryTokenStore) Put(ctx context.Context, userID string, token *Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	copied := *token
+	s.tokens[userID] = &copied
+	return nil
+}
+
+// Delete implements TokenStore
+func (s *MemoryTokenStore) Delete(ctx context.Context, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.tokens, userID)
+	return nil
+}
+
+// NewFileTokenStore initializes a FileTokenStore in the given directory, which is created if it does not exist,
+// using the given 16, 24 or 32-byte AES key
+func NewFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize uphold file token store cipher; %s", err.Error())
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize uphold file token store cipher; %s", err.Error())
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("Failed to create uphold file token store directory: %s; %s", dir, err.Error())
+	}
+
+	return &FileTokenStore{
+		dir:  dir,
+		aead: aead,
+	}, nil
+}
+
+// Get implements TokenStore
+func (s *FileTokenStore) Get(ctx context.Context, userID string) (*Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ciphertext, err := os.ReadFile(s.path(userID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("Failed to decrypt uphold token for user: %s; file is truncated", userID)
+	}
+
+	plaintext, err := s.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(userID))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt uphold token for user: %s; %s", userID, err.Error())
+	}
+
+	var token *Token
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal uphold token for user: %s; %s", userID, err.Error())
+	}
+	return token, nil
+}
+
+// Put implements TokenStore; the token is written to a temporary file which then replaces any previously stored token
+func (s *FileTokenStore) Put(ctx context.Context, userID string, token *Token) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	ciphertext := s.aead.Seal(nonce, nonce, plaintext, []byte(userID))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(ciphertext); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(userID))
+}
+
+// Delete implements TokenStore
+func (s *FileTokenStore) Delete(ctx context.Context, userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := os.Remove(s.path(userID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileTokenStore) path(userID string) string {
+	digest := sha256.Sum256([]byte(userID))
+	return filepath.Join(s.dir, hex.EncodeToString(digest[:])+fileTokenStoreExtension)
+}
+
+// WithTokenStore returns a copy of the Client which resolves the tokens of users passed to ForUser from the given TokenStore
+func (c *Client) WithTokenStore(store TokenStore) *Client {
+	client := *c
+	client.tokenStore = store
+	return &client
+}
+
+// ForUser returns a copy of the Client bound to the token stored for the given user; bearer token parameters
+// should be passed as empty strings to methods of the returned Client, which loads the token from the TokenStore,
+// refreshing and persisting it when it expires. Refresh requests are bound to the given context.
+func (c *Client) ForUser(ctx context.Context, userID string) (*Client, error) {
+	if c.tokenStore == nil {
+		return nil, fmt.Errorf("Failed to resolve uphold client for user: %s; no token store configured", userID)
+	}
+
+	return c.WithTokenSource(&storeTokenSource{
+		ctx:    ctx,
+		client: c,
+		userID: userID,
+	}), nil
+}
+
+// Token implements TokenSource; refreshes are serialized per user across all copies of the Client, so a
+// refresh token is never redeemed more than once
+func (s *storeTokenSource) Token() (*Token, error) {
+	lock := s.client.userLock(s.userID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	token, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Valid() {
+		return token, nil
+	}
+
+	refreshed, err := s.client.refreshToken(s.ctx, token)
+	if err != nil {
+		log.Warningf("Failed to refresh uphold token for user: %s; %s", s.userID, err.Error())
+		return nil, err
+	}
+
+	s.persist(refreshed)
+	return refreshed, nil
+}
+
+// load returns the refreshed token which previously could not be persisted, retrying its persistence, or otherwise the stored token
+func (s *storeTokenSource) load() (*Token, error) {
+	if unpersisted, unpersistedOk := s.client.unpersistedTokens.Load(s.userID); unpersistedOk {
+		token := unpersisted.(*Token)
+		s.persist(token)
+		return token, nil
+	}
+
+	token, err := s.client.tokenStore.Get(s.ctx, s.userID)
+	if err != nil {
+		log.Warningf("Failed to load uphold token for user: %s; %s", s.userID, err.Error())
+		return nil, err
+	}
+	return token, nil
+}
+
+// persist stores the given refreshed token; the refresh token it replaced has already been redeemed, so when the
+// TokenStore fails the token is retained in memory, and persisting it is retried when the token is next resolved
+func (s *storeTokenSource) persist(token *Token) {
+	if err := s.client.tokenStore.Put(s.ctx, s.userID, token); err != nil {
+		log.Warningf("Failed to persist refreshed uphold token for user: %s; retaining it in memory; %s", s.userID, err.Error())
+		s.client.unpersistedTokens.Store(s.userID, token)
+		return
+	}
+
+	s.client.unpersistedTokens.Delete(s.userID)
+	log.Debugf("Persisted refreshed uphold token for user: %s", s.userID)
+}
+
+// userLock returns the mutex which serializes token refreshes for the given user
+func (c *Client) userLock(userID string) *sync.Mutex {
+	lock, _ := c.userLocks.LoadOrStore(userID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
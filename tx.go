@@ -1,51 +1,475 @@
 package uphold
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
-// CommitTransaction commits a previously quoted transaction
-func CommitTransaction(token, cardID, transactionID string) (*Transaction, error) {
+	uuid "github.com/kthomas/go.uuid"
+)
+
+// Transaction destination kinds
+const (
+	DestinationAny      = ""         // an email address, username, card id or crypto address, resolved by uphold
+	DestinationEmail    = "email"    // an email address
+	DestinationUsername = "username" // the username of an uphold user
+	DestinationCard     = "card"     // the id of an uphold card
+	DestinationAddress  = "address"  // a crypto address
+)
+
+// TransactionDestination describes the recipient of a transaction
+type TransactionDestination struct {
+	Kind    string  // the kind of destination, e.g., DestinationEmail
+	Value   string  // the email address, username, card id or crypto address
+	Network *string // the network of a crypto address, e.g., NetworkBitcoin; inferred by uphold when nil
+	Tag     *string // the destination tag or memo required by some networks, e.g., NetworkXRPLedger
+}
+
+// TransactionRequest describes a transaction to be created on the uphold platform
+type TransactionRequest struct {
+	Currency     string                  // the currency in which the amount is denominated
+	Amount       Amount                  // the amount to be transferred
+	Destination  *TransactionDestination // the recipient of the transaction
+	Message      *string                 // a message or note to the recipient
+	Reference    *string                 // a reference assigned to the transaction by the caller
+	SecurityCode *string                 // the security code of the origin card, required for card deposits
+	Priority     *TransactionPriority    // the priority of the transaction; TxPriorityNormal or TxPriorityFast
+	Commit       bool                    // when true, the transaction is committed in a single step rather than quoted
+}
+
+// TransactionFilter narrows the transactions returned when listing transaction history; uphold does not
+// support filtering, so filters are applied client-side as each page is fetched. Empty filters match all transactions.
+type TransactionFilter struct {
+	Statuses []TransactionStatus // the statuses to include, e.g., TxStatusPending or TxStatusCompleted
+	Types    []TransactionType   // the types to include, e.g., TxTypeDeposit, TxTypeTransfer or TxTypeWithdrawal
+	Since    *time.Time          // when non-nil, only transactions created at or after this time are included
+	Until    *time.Time          // when non-nil, only transactions created before this time are included
+}
+
+// EmailDestination returns a TransactionDestination for the given email address
+func EmailDestination(email string) *TransactionDestination {
+	return &TransactionDestination{Kind: DestinationEmail, Value: email}
+}
+
+// UsernameDestination returns a TransactionDestination for the given uphold username
+func UsernameDestination(username string) *TransactionDestination {
+	return &TransactionDestination{Kind: DestinationUsername, Value: username}
+}
+
+// CardDestination returns a TransactionDestination for the given uphold card id
+func CardDestination(cardID string) *TransactionDestination {
+	return &TransactionDestination{Kind: DestinationCard, Value: cardID}
+}
+
+// CryptoDestination returns a TransactionDestination for the given crypto address on the given network;
+// the tag is omitted when empty
+func CryptoDestination(network, address, tag string) *TransactionDestination {
+	return &TransactionDestination{
+		Kind:    DestinationAddress,
+		Value:   address,
+		Network: stringOrNil(network),
+		Tag:     stringOrNil(tag),
+	}
+}
+
+// Validate returns a *ValidationError if the destination is malformed
+func (d *TransactionDestination) Validate() error {
+	value := strings.TrimSpace(d.Value)
+	if value == "" {
+		return &ValidationError{Field: "destination", Message: "destination is required"}
+	}
+
+	switch d.Kind {
+	case DestinationAny, DestinationUsername:
+	case DestinationEmail:
+		if at := strings.Index(value, "@"); at < 1 || at == len(value)-1 {
+			return &ValidationError{Field: "destination", Message: fmt.Sprintf("%s is not a valid email address", value)}
+		}
+	case DestinationCard:
+		if _, err := uuid.FromString(value); err != nil {
+			return &ValidationError{Field: "destination", Message: fmt.Sprintf("%s is not a valid card id", value)}
+		}
+	case DestinationAddress:
+		if strings.ContainsAny(value, " ?&#") {
+			return &ValidationError{Field: "destination", Message: fmt.Sprintf("%s is not a valid crypto address", value)}
+		}
+	default:
+		return &ValidationError{Field: "destination", Message: fmt.Sprintf("unsupported destination kind: %s", d.Kind)}
+	}
+
+	if d.Kind != DestinationAny && d.Kind != DestinationAddress && (d.Network != nil || d.Tag != nil) {
+		return &ValidationError{Field: "destination", Message: "network and tag are only supported for crypto addresses"}
+	}
+
+	if d.Tag != nil && *d.Tag != "" {
+		if strings.ContainsAny(*d.Tag, "?&#") || strings.ContainsFunc(*d.Tag, unicode.IsSpace) {
+			return &ValidationError{Field: "destination.tag", Message: fmt.Sprintf("%q is not a valid destination tag", *d.Tag)}
+		}
+		if d.Network != nil && *d.Network == NetworkXRPLedger {
+			if _, err := strconv.ParseUint(*d.Tag, 10, 32); err != nil {
+				return &ValidationError{Field: "destination.tag", Message: fmt.Sprintf("%q is not a valid XRP Ledger destination tag; tags are 32-bit unsigned integers", *d.Tag)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// transactionCommitScopes are the scopes with which transactions are created, any one of which permits committing a quote
+var transactionCommitScopes = []Scope{
+	ScopeTransactionsDeposit,
+	ScopeTransactionsTransferApplication,
+	ScopeTransactionsTransferOthers,
+	ScopeTransactionsTransferSelf,
+	ScopeTransactionsWithdraw,
+}
+
+// requiredScopes returns the scopes a token must have been granted to transfer to the destination; transfers
+// to card ids are not checked, as the card may belong to either the sender or another user
+func (d *TransactionDestination) requiredScopes() []Scope {
+	switch d.Kind {
+	case DestinationEmail, DestinationUsername:
+		return []Scope{ScopeTransactionsTransferOthers}
+	case DestinationAddress:
+		return []Scope{ScopeTransactionsWithdraw}
+	}
+	return nil
+}
+
+// String returns the destination as expected by uphold, with the destination tag, if any, escaped and appended as the dt query parameter
+func (d *TransactionDestination) String() string {
+	if d.Tag != nil && *d.Tag != "" {
+		return fmt.Sprintf("%s?dt=%s", strings.TrimSpace(d.Value), url.QueryEscape(*d.Tag))
+	}
+	return strings.TrimSpace(d.Value)
+}
+
+// Validate returns a *ValidationError if the request is incomplete or malformed
+func (r *TransactionRequest) Validate() error {
+	if strings.TrimSpace(r.Currency) == "" {
+		return &ValidationError{Field: "denomination.currency", Message: "currency is required"}
+	}
+
+	if r.Amount.Sign() <= 0 {
+		return &ValidationError{Field: "denomination.amount", Message: "amount must be greater than zero"}
+	}
+
+	if r.Destination == nil {
+		return &ValidationError{Field: "destination", Message: "destination is required"}
+	}
+	if err := r.Destination.Validate(); err != nil {
+		return err
+	}
+
+	if r.Priority != nil && !r.Priority.IsKnown() {
+		return &ValidationError{Field: "priority", Message: fmt.Sprintf("unsupported priority: %s", *r.Priority)}
+	}
+
+	if r.SecurityCode != nil && strings.TrimSpace(*r.SecurityCode) == "" {
+		return &ValidationError{Field: "securityCode", Message: "security code must not be blank"}
+	}
+
+	return nil
+}
+
+// params returns the request body expected by uphold
+func (r *TransactionRequest) params() map[string]interface{} {
+	params := map[string]interface{}{
+		"denomination": map[string]interface{}{
+			"amount":   r.Amount.String(),
+			"currency": strings.ToUpper(strings.TrimSpace(r.Currency)),
+		},
+		"destination": r.Destination.String(),
+	}
+
+	if r.Destination.Network != nil {
+		params["network"] = *r.Destination.Network
+	}
+	if r.Message != nil {
+		params["message"] = *r.Message
+	}
+	if r.Reference != nil {
+		params["reference"] = *r.Reference
+	}
+	if r.SecurityCode != nil {
+		params["securityCode"] = *r.SecurityCode
+	}
+	if r.Priority != nil {
+		params["priority"] = *r.Priority
+	}
+
+	return params
+}
+
+// CommitTransaction commits a previously quoted transaction; the token must be granted one of the scopes with which
+// transactions are created, which depends on the destination of the quoted transaction
+func (c *Client) CommitTransaction(ctx context.Context, token, cardID, transactionID string) (*Transaction, error) {
 	var tx *Transaction
 	var err error
 
-	client, err := NewUpholdAPIClient(stringOrNil(token), stringOrNil("/v0/me/"))
+	client, err := c.bearerAPIClientAny(token, "/v0/me", transactionCommitScopes...)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := client.Post(fmt.Sprintf("cards/%s/transactions/%s/commit", cardID, transactionID), nil, &tx)
+	status, err := client.PostContext(ctx, fmt.Sprintf("cards/%s/transactions/%s/commit", cardID, transactionID), nil, &tx)
 	if err != nil {
-		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", upholdClientID, err.Error())
+		log.Warningf("Failed to commit transaction (tx id: %s) on behalf of client id: %s; %s", transactionID, c.config.ClientID, err.Error())
 		return nil, err
 	}
 
-	log.Debugf("Received %d status code when attempting to commit transaction (tx id: %s) on behalf of client id: %s; response: %s", status, transactionID, upholdClientID, tx)
+	log.Debugf("Received %d status code when attempting to commit transaction (tx id: %s) on behalf of client id: %s; response: %s", status, transactionID, c.config.ClientID, tx)
 
 	return tx, err
 }
 
 // CreateTransaction submits a transaction to the Uphold platform but does not commit it for settlement
-func CreateTransaction(token, cardID, currency, destination string, amount float64) (*Transaction, error) {
+func (c *Client) CreateTransaction(ctx context.Context, token, cardID, currency, destination string, amount float64) (*Transaction, error) {
+	return c.SubmitTransaction(ctx, token, cardID, &TransactionRequest{
+		Currency:    currency,
+		Amount:      NewAmountFromFloat(amount),
+		Destination: &TransactionDestination{Value: destination},
+	})
+}
+
+// SubmitTransaction validates and submits the given transaction request to the Uphold platform from the card with the given id;
+// the transaction is quoted, and must subsequently be committed, unless the request opts into one-step commit
+func (c *Client) SubmitTransaction(ctx context.Context, token, cardID string, req *TransactionRequest) (*Transaction, error) {
 	var tx *Transaction
 	var err error
 
-	client, err := NewUpholdAPIClient(stringOrNil(token), stringOrNil("/v0/me/"))
+	if err := req.Validate(); err != nil {
+		log.Warningf("Failed to validate transaction request on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	client, err := c.bearerAPIClient(token, "/v0/me", req.Destination.requiredScopes()...)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := client.Post(fmt.Sprintf("cards/%s/transactions", cardID), map[string]interface{}{
-		"demonination": map[string]interface{}{
-			"amount":   amount,
-			"currency": currency,
-		},
-		"destination": destination,
-	}, &tx)
+	uri := fmt.Sprintf("cards/%s/transactions", cardID)
+	if req.Commit {
+		uri = fmt.Sprintf("%s?commit=true", uri)
+	}
+
+	status, err := client.PostContext(ctx, uri, req.params(), &tx)
+	if err != nil {
+		log.Warningf("Failed to create transaction on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Received %d status code in response to attempted transaction creation API call on behalf of client id: %s; response: %s", status, c.config.ClientID, tx)
+
+	return tx, err
+}
+
+// CancelTransaction cancels a pending transaction, e.g., a transfer to an email address which has not yet been claimed;
+// a *TransactionStateError is returned if the transaction can no longer be cancelled
+func (c *Client) CancelTransaction(ctx context.Context, token, cardID, transactionID string) (*Transaction, error) {
+	return c.transitionTransaction(ctx, token, cardID, transactionID, "cancel")
+}
+
+// ResendTransaction resends the notification of a pending transaction to its recipient; a *TransactionStateError
+// is returned if the transaction is no longer pending
+func (c *Client) ResendTransaction(ctx context.Context, token, cardID, transactionID string) (*Transaction, error) {
+	return c.transitionTransaction(ctx, token, cardID, transactionID, "resend")
+}
+
+// transitionTransaction invokes the given operation, e.g., cancel, on the transaction with the given id
+func (c *Client) transitionTransaction(ctx context.Context, token, cardID, transactionID, operation string) (*Transaction, error) {
+	var tx *Transaction
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeTransactionsTransferOthers)
 	if err != nil {
-		log.Warningf("Failed to authorize client credentials on behalf of client id: %s; %s", upholdClientID, err.Error())
 		return nil, err
 	}
 
-	log.Debugf("Received %d status code in response to attempted transaction creation API call on behalf of client id: %s; response: %s", status, upholdClientID, tx)
+	status, err := client.PostContext(ctx, fmt.Sprintf("cards/%s/transactions/%s/%s", cardID, transactionID, operation), nil, &tx)
+	if err != nil {
+		if apiErr, ok := AsError(err); ok && transactionStateStatusCodes[apiErr.StatusCode] {
+			err = &TransactionStateError{
+				TransactionID: transactionID,
+				Operation:     operation,
+				APIError:      apiErr,
+			}
+		}
+		log.Warningf("Failed to %s transaction (tx id: %s) on behalf of client id: %s; %s", operation, transactionID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Received %d status code when attempting to %s transaction (tx id: %s) on behalf of client id: %s; response: %s", status, operation, transactionID, c.config.ClientID, tx)
 
 	return tx, err
 }
+
+// TransactionsPager returns a Pager which walks the transactions of the user for the given bearer token, most recent first
+func (c *Client) TransactionsPager(token string) (*Pager[*Transaction], error) {
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeTransactionsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPager[*Transaction](client, "transactions", nil, maxPageSize), nil
+}
+
+// CardTransactionsPager returns a Pager which walks the transactions of the card with the given id, most recent first
+func (c *Client) CardTransactionsPager(token, cardID string) (*Pager[*Transaction], error) {
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeTransactionsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPager[*Transaction](client, fmt.Sprintf("cards/%s/transactions", cardID), nil, maxPageSize), nil
+}
+
+// ListTransactions fetches the transactions of the user for the given bearer token which match the given filter, which may be nil
+func (c *Client) ListTransactions(ctx context.Context, token string, filter *TransactionFilter) ([]*Transaction, error) {
+	pager, err := c.TransactionsPager(token)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := filterTransactions(ctx, pager, filter)
+	if err != nil {
+		log.Warningf("Failed to list uphold transactions on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Fetched %d uphold transaction(s) on behalf of client id: %s", len(txs), c.config.ClientID)
+	return txs, nil
+}
+
+// ListCardTransactions fetches the transactions of the card with the given id which match the given filter, which may be nil
+func (c *Client) ListCardTransactions(ctx context.Context, token, cardID string, filter *TransactionFilter) ([]*Transaction, error) {
+	pager, err := c.CardTransactionsPager(token, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	txs, err := filterTransactions(ctx, pager, filter)
+	if err != nil {
+		log.Warningf("Failed to list transactions of uphold card %s on behalf of client id: %s; %s", cardID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	log.Debugf("Fetched %d transaction(s) of uphold card %s on behalf of client id: %s", len(txs), cardID, c.config.ClientID)
+	return txs, nil
+}
+
+// GetTransaction fetches the transaction with the given id for the given bearer token
+func (c *Client) GetTransaction(ctx context.Context, token, transactionID string) (*Transaction, error) {
+	return c.getTransaction(ctx, token, fmt.Sprintf("transactions/%s", transactionID), transactionID)
+}
+
+// GetCardTransaction fetches the transaction with the given id from the card with the given id
+func (c *Client) GetCardTransaction(ctx context.Context, token, cardID, transactionID string) (*Transaction, error) {
+	return c.getTransaction(ctx, token, fmt.Sprintf("cards/%s/transactions/%s", cardID, transactionID), transactionID)
+}
+
+func (c *Client) getTransaction(ctx context.Context, token, uri, transactionID string) (*Transaction, error) {
+	var tx *Transaction
+	var err error
+
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeTransactionsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.GetContext(ctx, uri, nil, &tx)
+	if err != nil {
+		log.Warningf("Failed to fetch uphold transaction (tx id: %s) on behalf of client id: %s; %s", transactionID, c.config.ClientID, err.Error())
+		return nil, err
+	}
+
+	if status == 200 {
+		log.Debugf("Fetched uphold transaction (tx id: %s) on behalf of client id: %s", transactionID, c.config.ClientID)
+		return tx, nil
+	}
+
+	return nil, fmt.Errorf("Failed to fetch uphold transaction (tx id: %s); status: %d", transactionID, status)
+}
+
+// filterTransactions walks the given pager, returning the transactions which match the given filter; as uphold
+// returns the most recent transactions first, paging stops once a transaction created before filter.Since is seen
+func filterTransactions(ctx context.Context, pager *Pager[*Transaction], filter *TransactionFilter) ([]*Transaction, error) {
+	txs := make([]*Transaction, 0)
+	for tx, err := range pager.Items(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil && filter.Since != nil && tx.CreatedAt != nil && tx.CreatedAt.Before(*filter.Since) {
+			break
+		}
+		if filter.matches(tx) {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// matches returns true if the given transaction satisfies the filter; a nil filter matches all transactions
+func (f *TransactionFilter) matches(tx *Transaction) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Statuses) > 0 && (tx.Status == nil || !slices.Contains(f.Statuses, *tx.Status)) {
+		return false
+	}
+
+	if len(f.Types) > 0 && (tx.Type == nil || !slices.Contains(f.Types, *tx.Type)) {
+		return false
+	}
+
+	if f.Since != nil || f.Until != nil {
+		if tx.CreatedAt == nil {
+			return false
+		}
+		if f.Since != nil && tx.CreatedAt.Before(*f.Since) {
+			return false
+		}
+		if f.Until != nil && !tx.CreatedAt.Before(*f.Until) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CommitTransaction commits a previously quoted transaction using the environment-configured client
+func CommitTransaction(token, cardID, transactionID string) (*Transaction, error) {
+	return CommitTransactionContext(context.Background(), token, cardID, transactionID)
+}
+
+// CommitTransactionContext commits a previously quoted transaction using the environment-configured client;
+// the request is bound to the given context
+func CommitTransactionContext(ctx context.Context, token, cardID, transactionID string) (*Transaction, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.CommitTransaction(ctx, token, cardID, transactionID)
+}
+
+// CreateTransaction submits a transaction to the Uphold platform using the environment-configured client
+// but does not commit it for settlement
+func CreateTransaction(token, cardID, currency, destination string, amount float64) (*Transaction, error) {
+	return CreateTransactionContext(context.Background(), token, cardID, currency, destination, amount)
+}
+
+// CreateTransactionContext submits a transaction to the Uphold platform using the environment-configured client
+// but does not commit it for settlement; the request is bound to the given context
+func CreateTransactionContext(ctx context.Context, token, cardID, currency, destination string, amount float64) (*Transaction, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateTransaction(ctx, token, cardID, currency, destination, amount)
+}
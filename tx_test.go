@@ -0,0 +1,37 @@
+package uphold
+
+import (
+	"testing"
+)
+
+func TestTransactionDestinationTag(t *testing.T) {
+	tests := []struct {
+		destination *TransactionDestination
+		valid       bool
+		str         string
+	}{
+		{CryptoDestination(NetworkXRPLedger, "rAddr", "12345"), true, "rAddr?dt=12345"},
+		{CryptoDestination(NetworkXRPLedger, "rAddr", "12&x=1"), false, ""},
+		{CryptoDestination(NetworkXRPLedger, "rAddr", "memo"), false, ""},
+		{CryptoDestination(NetworkXRPLedger, "rAddr", "4294967296"), false, ""},
+		{CryptoDestination(NetworkStellar, "GAddr", "memo text"), false, ""},
+		{CryptoDestination(NetworkStellar, "GAddr", "memo#1"), false, ""},
+		{CryptoDestination(NetworkStellar, "GAddr", "memo+1"), true, "GAddr?dt=memo%2B1"},
+		{CryptoDestination(NetworkBitcoin, "1Addr", ""), true, "1Addr"},
+		{CryptoDestination(NetworkBitcoin, "1Addr#x", ""), false, ""},
+	}
+
+	for _, test := range tests {
+		err := test.destination.Validate()
+		if test.valid != (err == nil) {
+			t.Errorf("Validate(%s) = %v; want valid: %v", test.destination, err, test.valid)
+			continue
+		}
+		if err != nil && !IsValidationFailed(err) {
+			t.Errorf("Validate(%s) returned %T; want *ValidationError", test.destination.Value, err)
+		}
+		if test.valid && test.destination.String() != test.str {
+			t.Errorf("String() = %q; want %q", test.destination.String(), test.str)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package uphold
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // CreateUser creates a new Uphold user
-func CreateUser(email, password string, country, locale, accountType *string) (*User, error) {
+func (c *Client) CreateUser(ctx context.Context, email, password string, country, locale, accountType *string) (*User, error) {
 	var user *User
 	var err error
 
-	client, err := NewUnauthorizedAPIClient(stringOrNil("/v0/users"))
+	client, err := c.NewUnauthorizedAPIClient(stringOrNil("/v0/users"))
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +27,7 @@ func CreateUser(email, password string, country, locale, accountType *string) (*
 		accountType = stringOrNil("business")
 	}
 
-	status, err := client.Post("", map[string]interface{}{
+	status, err := client.PostContext(ctx, "", map[string]interface{}{
 		"country":  country,
 		"email":    email,
 		"password": password,
@@ -45,7 +48,7 @@ func CreateUser(email, password string, country, locale, accountType *string) (*
 		},
 	}, &user)
 	if err != nil {
-		log.Warningf("Failed to create uphold user; %s", upholdClientID, err.Error())
+		log.Warningf("Failed to create uphold user; %s", err.Error())
 		return nil, err
 	}
 
@@ -58,16 +61,17 @@ func CreateUser(email, password string, country, locale, accountType *string) (*
 }
 
 // CreateDocument upserts a document on behalf of an uphold account holder
-func CreateDocument(token, documentType string, value interface{}) error {
+func (c *Client) CreateDocument(ctx context.Context, token, documentType string, value interface{}) error {
 	var resp map[string]interface{}
 	var err error
 
-	client, err := NewUpholdAPIClient(stringOrNil(token), stringOrNil("/v0/me"))
+	// uphold does not document a scope specific to documents, so none is checked before the request is sent
+	client, err := c.bearerAPIClient(token, "/v0/me")
 	if err != nil {
 		return err
 	}
 
-	status, err := client.Post("documents", map[string]interface{}{
+	status, err := client.PostContext(ctx, "documents", map[string]interface{}{
 		"type":  documentType,
 		"value": value,
 	}, &resp)
@@ -77,7 +81,7 @@ func CreateDocument(token, documentType string, value interface{}) error {
 	}
 
 	if status == 200 {
-		log.Warningf("Created document on behalf of uphold user; %s", err.Error())
+		log.Debugf("Created document on behalf of uphold user")
 		return nil
 	}
 
@@ -85,52 +89,94 @@ func CreateDocument(token, documentType string, value interface{}) error {
 }
 
 // AddPhone adds a phone to an uphold account
-func AddPhone(token, countryCode, phone string) error {
+func (c *Client) AddPhone(ctx context.Context, token, countryCode, phone string) error {
 	var resp map[string]interface{}
 	var err error
 
-	client, err := NewUpholdAPIClient(stringOrNil(token), stringOrNil("/v0/me"))
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopePhonesWrite)
 	if err != nil {
 		return err
 	}
 
-	status, err := client.Post("phones", map[string]interface{}{
+	status, err := client.PostContext(ctx, "phones", map[string]interface{}{
 		"countryCode": countryCode,
 		"phone":       phone,
 	}, &resp)
 	if err != nil {
-		log.Warningf("Failed to create document on behalf of uphold user; %s", err.Error())
+		log.Warningf("Failed to add phone on behalf of uphold user; %s", err.Error())
 		return err
 	}
 
 	if status == 200 {
-		log.Warningf("Created document on behalf of uphold user; %s", err.Error())
+		log.Debugf("Added phone on behalf of uphold user")
 		return nil
 	}
 
-	return fmt.Errorf("Failed to create document on behalf of uphold user; status: %d", status)
+	return fmt.Errorf("Failed to add phone on behalf of uphold user; status: %d", status)
 }
 
 // GetUser fetches the user for the given bearer token
-func GetUser(token string) (*User, error) {
+func (c *Client) GetUser(ctx context.Context, token string) (*User, error) {
 	var user *User
 	var err error
 
-	client, err := NewUpholdAPIClient(stringOrNil(token), stringOrNil("/v0/me"))
+	client, err := c.bearerAPIClient(token, "/v0/me", ScopeUserRead)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := client.Get("", nil, &user)
+	status, err := client.GetContext(ctx, "", nil, &user)
 	if err != nil {
-		log.Warningf("Failed to fetch uphold user on behalf of client id: %s; %s", upholdClientID, err.Error())
+		log.Warningf("Failed to fetch uphold user on behalf of client id: %s; %s", c.config.ClientID, err.Error())
 		return nil, err
 	}
 
 	if status == 200 {
-		log.Debugf("Fetched uphold user %s on behalf of client id: %s; response: %s", *user.ID, upholdClientID)
+		log.Debugf("Fetched uphold user %s on behalf of client id: %s", *user.ID, c.config.ClientID)
 		return user, nil
 	}
 
 	return nil, fmt.Errorf("Failed to fetch uphold user; status: %d", status)
 }
+
+// CreateUser creates a new Uphold user using the environment-configured client
+func CreateUser(email, password string, country, locale, accountType *string) (*User, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateUser(context.Background(), email, password, country, locale, accountType)
+}
+
+// CreateDocument upserts a document on behalf of an uphold account holder using the environment-configured client
+func CreateDocument(token, documentType string, value interface{}) error {
+	client, err := DefaultClient()
+	if err != nil {
+		return err
+	}
+	return client.CreateDocument(context.Background(), token, documentType, value)
+}
+
+// AddPhone adds a phone to an uphold account using the environment-configured client
+func AddPhone(token, countryCode, phone string) error {
+	client, err := DefaultClient()
+	if err != nil {
+		return err
+	}
+	return client.AddPhone(context.Background(), token, countryCode, phone)
+}
+
+// GetUser fetches the user for the given bearer token using the environment-configured client
+func GetUser(token string) (*User, error) {
+	return GetUserContext(context.Background(), token)
+}
+
+// GetUserContext fetches the user for the given bearer token using the environment-configured client;
+// the request is bound to the given context
+func GetUserContext(ctx context.Context, token string) (*User, error) {
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetUser(ctx, token)
+}
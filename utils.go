@@ -1,18 +1,130 @@
 package uphold
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"net/url"
 )
 
-// WebAuthorizationURL returns the webapp authorization URL for the given scope
+// stateEntropyBytes is the number of random bytes encoded in an OAuth state parameter
+const stateEntropyBytes = 32
+
+// pkceVerifierEntropyBytes is the number of random bytes encoded in a PKCE code verifier, yielding the
+// 43-character minimum verifier length permitted by RFC 7636
+const pkceVerifierEntropyBytes = 32
+
+const pkceChallengeMethodS256 = "S256"
+
+// AuthorizationURLOption configures optional parameters of a webapp authorization URL
+type AuthorizationURLOption func(url.Values)
+
+// WithState includes the given state parameter, which uphold returns alongside the authorization code
+func WithState(state string) AuthorizationURLOption {
+	return func(params url.Values) {
+		params.Set("state", state)
+	}
+}
+
+// WithRedirectURI includes the given redirect_uri parameter, which must match a redirect URI registered for the application
+func WithRedirectURI(redirectURI string) AuthorizationURLOption {
+	return func(params url.Values) {
+		params.Set("redirect_uri", redirectURI)
+	}
+}
+
+// PKCE holds a PKCE code verifier and its S256 code challenge; the challenge is included in the authorization
+// URL using WithPKCE and the verifier is sent in place of the client secret when exchanging the authorization code
+type PKCE struct {
+	Verifier        string
+	Challenge       string
+	ChallengeMethod string
+}
+
+// NewPKCE generates a cryptographically secure random code verifier and its S256 code challenge
+func NewPKCE() (*PKCE, error) {
+	entropy := make([]byte, pkceVerifierEntropyBytes)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(entropy)
+
+	return &PKCE{
+		Verifier:        verifier,
+		Challenge:       pkceChallengeS256(verifier),
+		ChallengeMethod: pkceChallengeMethodS256,
+	}, nil
+}
+
+// pkceChallengeS256 returns the S256 code challenge of the given code verifier, as defined by RFC 7636 section 4.2
+func pkceChallengeS256(verifier string) string {
+	digest := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// WithPKCE includes the code_challenge and code_challenge_method parameters for the given PKCE verifier
+func WithPKCE(pkce *PKCE) AuthorizationURLOption {
+	return func(params url.Values) {
+		params.Set("code_challenge", pkce.Challenge)
+		params.Set("code_challenge_method", pkce.ChallengeMethod)
+	}
+}
+
+// NewState returns a cryptographically secure random string suitable for use as the OAuth state parameter
+func NewState() (string, error) {
+	entropy := make([]byte, stateEntropyBytes)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(entropy), nil
+}
+
+// WebAuthorizationURL returns the webapp authorization URL for the given space-separated scope
+func (c *Client) WebAuthorizationURL(scope string) string {
+	return c.AuthorizationURL(ParseScopes(scope))
+}
+
+// AuthorizationURL returns the webapp authorization URL for the given scopes and optional parameters
+func (c *Client) AuthorizationURL(scopes Scopes, opts ...AuthorizationURLOption) string {
+	params := url.Values{}
+	params.Set("scope", scopes.String())
+	for _, opt := range opts {
+		opt(params)
+	}
+	return fmt.Sprintf("%s/authorize/%s?%s", c.config.BaseURL, c.config.ClientID, params.Encode())
+}
+
+// NewAuthorizationURL generates a new state parameter and returns the webapp authorization URL for the given scopes
+// which includes it; the caller must persist the state, e.g., in the user's session, and verify it upon callback
+func (c *Client) NewAuthorizationURL(scopes Scopes, opts ...AuthorizationURLOption) (authURL, state string, err error) {
+	state, err = NewState()
+	if err != nil {
+		log.Warningf("Failed to generate OAuth state parameter on behalf of client id: %s; %s", c.config.ClientID, err.Error())
+		return "", "", err
+	}
+	return c.AuthorizationURL(scopes, append(opts, WithState(state))...), state, nil
+}
+
+// WebAuthorizationAllScopesURL returns the webapp authorization URL requesting all supported scopes
+func (c *Client) WebAuthorizationAllScopesURL() string {
+	return c.AuthorizationURL(AllScopes)
+}
+
+// WebAuthorizationURL returns the webapp authorization URL for the given scope using the environment-configured client
 func WebAuthorizationURL(scope string) string {
-	return fmt.Sprintf("%s/authorize/%s?scope=%s", upholdBaseURL, upholdClientID, url.QueryEscape(scope))
+	client, err := DefaultClient()
+	if err != nil {
+		return ""
+	}
+	return client.WebAuthorizationURL(scope)
 }
 
 // WebAuthorizationAllScopesURL returns the webapp authorization URL requesting all supported scopes
+// using the environment-configured client
 func WebAuthorizationAllScopesURL() string {
-	return WebAuthorizationURL(upholdSupportedScopes)
+	return WebAuthorizationURL(AllScopes.String())
 }
 
 func stringOrNil(str string) *string {
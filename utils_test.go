@@ -0,0 +1,39 @@
+package uphold
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestPKCEChallengeS256 verifies the challenge against the example in RFC 7636 appendix B
+func TestPKCEChallengeS256(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if got := pkceChallengeS256(verifier); got != want {
+		t.Errorf("pkceChallengeS256(%q) = %q; want %q", verifier, got, want)
+	}
+}
+
+func TestNewPKCE(t *testing.T) {
+	pkce, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE returned error: %s", err)
+	}
+
+	// RFC 7636 section 4.1 requires 43 to 128 unreserved characters
+	if !regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`).MatchString(pkce.Verifier) {
+		t.Errorf("verifier %q is not a valid RFC 7636 code verifier", pkce.Verifier)
+	}
+	if pkce.Challenge != pkceChallengeS256(pkce.Verifier) || pkce.ChallengeMethod != "S256" {
+		t.Errorf("unexpected challenge %q (%s) for verifier %q", pkce.Challenge, pkce.ChallengeMethod, pkce.Verifier)
+	}
+
+	other, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE returned error: %s", err)
+	}
+	if other.Verifier == pkce.Verifier {
+		t.Errorf("NewPKCE returned the same verifier twice")
+	}
+}
@@ -0,0 +1,352 @@
+package uphold
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultWatchMinInterval = time.Second * 2
+const defaultWatchMaxInterval = time.Second * 30
+const watchIntervalMultiplier = 1.5
+
+// watchBatchPages is the number of pages of recent transactions fetched by a TransactionWatcher on each poll;
+// watched transactions which are not among them are fetched individually
+const watchBatchPages = 2
+
+// TransactionUpdate describes a status transition observed while watching a transaction
+type TransactionUpdate struct {
+	CardID         string            // the id of the card from which the transaction was sent
+	TransactionID  string            // the id of the transaction
+	PreviousStatus TransactionStatus // the previously observed status; empty for the first observation
+	Status         TransactionStatus // the observed status
+	Final          bool              // true if the observed status is final, after which the transaction is no longer watched
+	Transaction    *Transaction      // the transaction as most recently fetched
+	Err            error             // non-nil if the transaction could not be fetched
+}
+
+// WatchOptions configures how transactions are polled
+type WatchOptions struct {
+	MinInterval time.Duration            // the delay between polls immediately after a status transition
+	MaxInterval time.Duration            // the maximum delay between polls, reached by backing off while the status is unchanged
+	OnUpdate    func(*TransactionUpdate) // invoked for each update before it is sent on the channel, if non-nil
+}
+
+// TransactionWatcher polls many in-flight transactions of a single user, batching polls by fetching
+// the user's recent transactions rather than fetching each watched transaction individually
+type TransactionWatcher struct {
+	client  *Client
+	token   string
+	options *WatchOptions
+	updates chan *TransactionUpdate
+
+	mutex   sync.Mutex
+	watched map[string]*watchedTransaction
+}
+
+type watchedTransaction struct {
+	cardID string
+	status TransactionStatus
+}
+
+// WatchTransaction polls the transaction with the given id, backing off while its status is unchanged, and sends
+// each status transition on the returned channel; the channel is closed once a final status is observed, the
+// transaction cannot be fetched due to a non-transient error, e.g., it cannot be found or the token was rejected,
+// or the given context is done. Transient failures are sent as updates with a non-nil Err and polling backs off.
+func (c *Client) WatchTransaction(ctx context.Context, token, cardID, transactionID string, opts *WatchOptions) <-chan *TransactionUpdate {
+	options := resolveWatchOptions(opts)
+	updates := make(chan *TransactionUpdate, 1)
+
+	go func() {
+		defer close(updates)
+
+		status := TransactionStatus("")
+		interval := options.MinInterval
+
+		for {
+			update := &TransactionUpdate{
+				CardID:         cardID,
+				TransactionID:  transactionID,
+				PreviousStatus: status,
+			}
+
+			tx, err := c.GetCardTransaction(ctx, token, cardID, transactionID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				update.Err = err
+				update.Final = isFinalWatchError(err)
+				if !emitTransactionUpdate(ctx, updates, options, update) || update.Final {
+					return
+				}
+				interval = nextWatchInterval(interval, options)
+			} else if tx.Status != nil && *tx.Status != status {
+				update.Status = *tx.Status
+				update.Final = tx.Status.IsFinal()
+				update.Transaction = tx
+				status = *tx.Status
+				interval = options.MinInterval
+				if !emitTransactionUpdate(ctx, updates, options, update) || update.Final {
+					return
+				}
+			} else {
+				interval = nextWatchInterval(interval, options)
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return updates
+}
+
+// NewTransactionWatcher initializes a TransactionWatcher for the user with the given bearer token; transactions
+// are added using Add and polled once Run is invoked
+func (c *Client) NewTransactionWatcher(token string, opts *WatchOptions) *TransactionWatcher {
+	return &TransactionWatcher{
+		client:  c,
+		token:   token,
+		options: resolveWatchOptions(opts),
+		updates: make(chan *TransactionUpdate, 16),
+		watched: map[string]*watchedTransaction{},
+	}
+}
+
+// Add starts watching the transaction with the given id; it may be invoked before or while the watcher runs
+func (w *TransactionWatcher) Add(cardID, transactionID string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, watching := w.watched[transactionID]; !watching {
+		w.watched[transactionID] = &watchedTransaction{cardID: cardID}
+	}
+}
+
+// Remove stops watching the transaction with the given id
+func (w *TransactionWatcher) Remove(transactionID string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.watched, transactionID)
+}
+
+// Len returns the number of transactions being watched
+func (w *TransactionWatcher) Len() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return len(w.watched)
+}
+
+// Updates returns the channel on which status transitions of watched transactions are sent; it is closed when Run returns
+func (w *TransactionWatcher) Updates() <-chan *TransactionUpdate {
+	return w.updates
+}
+
+// Run polls the watched transactions until the given context is done, backing off while no status transitions
+// are observed or polls fail; transactions are no longer watched once a final status is observed or they cannot
+// be fetched due to a non-transient error, e.g., they cannot be found or the token was rejected
+func (w *TransactionWatcher) Run(ctx context.Context) error {
+	defer close(w.updates)
+
+	interval := w.options.MinInterval
+	for {
+		transitioned, failed := w.poll(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if transitioned && !failed {
+			interval = w.options.MinInterval
+		} else {
+			interval = nextWatchInterval(interval, w.options)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// poll fetches the status of all watched transactions, emitting updates for those which transitioned or
+// could not be fetched; it returns whether any transaction transitioned and whether any fetch failed
+func (w *TransactionWatcher) poll(ctx context.Context) (transitioned, failed bool) {
+	w.mutex.Lock()
+	pending := make(map[string]*watchedTransaction, len(w.watched))
+	for transactionID, watched := range w.watched {
+		pending[transactionID] = &watchedTransaction{cardID: watched.cardID, status: watched.status}
+	}
+	w.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return false, false
+	}
+
+	fetched := map[string]*Transaction{}
+
+	pager, err := w.client.TransactionsPager(w.token)
+	for i := 0; err == nil && i < watchBatchPages && pager.HasNext() && len(fetched) < len(pending); i++ {
+		var page []*Transaction
+		page, err = pager.Next(ctx)
+		if err == nil {
+			for _, tx := range page {
+				if tx.ID != nil {
+					if _, watching := pending[tx.ID.String()]; watching {
+						fetched[tx.ID.String()] = tx
+					}
+				}
+			}
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, true
+		}
+		log.Warningf("Failed to fetch recent uphold transactions while watching %d transaction(s); %s", len(pending), err.Error())
+		if isTokenWatchError(err) {
+			// the token was rejected, so none of the watched transactions can be fetched individually either
+			for transactionID, watched := range pending {
+				w.emit(ctx, &TransactionUpdate{
+					CardID:         watched.cardID,
+					TransactionID:  transactionID,
+					PreviousStatus: watched.status,
+					Final:          true,
+					Err:            err,
+				})
+			}
+			return false, true
+		}
+	}
+
+	for transactionID, watched := range pending {
+		if ctx.Err() != nil {
+			return transitioned, failed
+		}
+
+		update := &TransactionUpdate{
+			CardID:         watched.cardID,
+			TransactionID:  transactionID,
+			PreviousStatus: watched.status,
+		}
+
+		tx, fetchedOk := fetched[transactionID]
+		if !fetchedOk {
+			tx, err = w.client.GetCardTransaction(ctx, w.token, watched.cardID, transactionID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return transitioned, failed
+				}
+				update.Err = err
+				update.Final = isFinalWatchError(err)
+				failed = true
+				w.emit(ctx, update)
+				continue
+			}
+		}
+
+		if tx.Status == nil || *tx.Status == watched.status {
+			continue
+		}
+
+		update.Status = *tx.Status
+		update.Final = tx.Status.IsFinal()
+		update.Transaction = tx
+		transitioned = true
+		w.emit(ctx, update)
+	}
+
+	return transitioned, failed
+}
+
+// emit records the observed status of the transaction, which is no longer watched if the update is final, and sends the update
+func (w *TransactionWatcher) emit(ctx context.Context, update *TransactionUpdate) {
+	w.mutex.Lock()
+	if update.Final {
+		delete(w.watched, update.TransactionID)
+	} else if watched, watching := w.watched[update.TransactionID]; watching && update.Err == nil {
+		watched.status = update.Status
+	}
+	w.mutex.Unlock()
+
+	emitTransactionUpdate(ctx, w.updates, w.options, update)
+}
+
+// emitTransactionUpdate invokes the OnUpdate callback, if any, and sends the update on the given channel;
+// it returns false if the given context was done before the update could be sent
+func emitTransactionUpdate(ctx context.Context, updates chan<- *TransactionUpdate, options *WatchOptions, update *TransactionUpdate) bool {
+	if update.Err != nil {
+		log.Warningf("Failed to poll uphold transaction (tx id: %s); %s", update.TransactionID, update.Err.Error())
+	} else {
+		log.Debugf("Observed uphold transaction (tx id: %s) status transition: %s -> %s", update.TransactionID, update.PreviousStatus, update.Status)
+	}
+
+	if options.OnUpdate != nil {
+		options.OnUpdate(update)
+	}
+
+	select {
+	case updates <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isFinalWatchError returns true if the given error will recur on every poll, e.g., the transaction cannot be
+// found or the token cannot be used; uphold responds to such requests with a 4xx status code other than 429
+func isFinalWatchError(err error) bool {
+	if isTokenWatchError(err) {
+		return true
+	}
+	apiErr, ok := AsError(err)
+	return ok && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests
+}
+
+// isTokenWatchError returns true if the given error indicates the token cannot be used to fetch any transaction,
+// e.g., it was rejected, lacks a required scope or cannot be refreshed
+func isTokenWatchError(err error) bool {
+	if IsScopeMissing(err) || errors.Is(err, ErrNoRefreshToken) || errors.Is(err, ErrTokenNotFound) {
+		return true
+	}
+	apiErr, ok := AsError(err)
+	return ok && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
+
+func resolveWatchOptions(opts *WatchOptions) *WatchOptions {
+	options := &WatchOptions{
+		MinInterval: defaultWatchMinInterval,
+		MaxInterval: defaultWatchMaxInterval,
+	}
+	if opts != nil {
+		if opts.MinInterval > 0 {
+			options.MinInterval = opts.MinInterval
+		}
+		if opts.MaxInterval > 0 {
+			options.MaxInterval = opts.MaxInterval
+		}
+		options.OnUpdate = opts.OnUpdate
+	}
+	if options.MaxInterval < options.MinInterval {
+		options.MaxInterval = options.MinInterval
+	}
+	return options
+}
+
+func nextWatchInterval(interval time.Duration, options *WatchOptions) time.Duration {
+	next := time.Duration(float64(interval) * watchIntervalMultiplier)
+	if next > options.MaxInterval {
+		return options.MaxInterval
+	}
+	return next
+}